@@ -2,24 +2,51 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/lk2023060901/zeus-go/exmaples/grpc/basic/protos"
+	"github.com/lk2023060901/zeus-go/pkg/etcd"
+	"github.com/lk2023060901/zeus-go/pkg/registry"
+	"github.com/lk2023060901/zeus-go/pkg/rpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
-	conn, err := grpc.NewClient("192.168.1.239:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	endpoints := flag.String("etcd", "localhost:2379", "comma separated etcd endpoints")
+	player := flag.String("player", "player-1", "player id used as consistent hash key")
+	flag.Parse()
+
+	cfg := etcd.DefaultConfig()
+	cfg.Endpoints = strings.Split(*endpoints, ",")
+	etcdClient, err := etcd.New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	defer etcdClient.Close()
+
+	discovery := registry.NewDiscovery(etcdClient)
+	defer discovery.Stop(context.Background())
+
+	// 通过服务发现连接 chat 服务，同一玩家的流总是落在同一实例上
+	conn, err := rpc.NewClient(rpc.Target("chat"),
+		rpc.WithDialOptions(
+			grpc.WithResolvers(rpc.NewResolver(discovery)),
+			rpc.WithBalancer(rpc.ConsistentHash),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		),
+	)
 	if err != nil {
 		panic(err)
 	}
 	defer conn.Close()
 
 	client := protos.NewChatServiceClient(conn)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(rpc.WithHashKey(context.Background(), *player))
 	defer cancel()
 
 	stream, err := client.Chat(ctx)
@@ -1,59 +1,102 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"io"
-	"net"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/lk2023060901/zeus-go/exmaples/grpc/basic/protos"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/peer"
+	"github.com/lk2023060901/zeus-go/pkg/app"
+	"github.com/lk2023060901/zeus-go/pkg/etcd"
+	"github.com/lk2023060901/zeus-go/pkg/registry"
+	"github.com/lk2023060901/zeus-go/pkg/rpc"
+	"github.com/lk2023060901/zeus-go/pkg/session"
 )
 
 type chatServer struct {
 	protos.UnimplementedChatServiceServer
+	sessions *session.Manager[*protos.ChatMessage]
 }
 
 func (s *chatServer) Chat(stream protos.ChatService_ChatServer) error {
-	ctx := stream.Context()
-	p, _ := peer.FromContext(ctx)
-	clientAddr := "unknown"
-	if p != nil {
-		clientAddr = p.Addr.String()
-	}
+	return rpc.ServeStream(s.sessions, stream, s.handle)
+}
 
-	for {
-		msg, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			return err
+// handle 回显收到的消息，并转发给其他在线会话
+func (s *chatServer) handle(sess *session.Session[*protos.ChatMessage], msg *protos.ChatMessage) error {
+	reply := &protos.ChatMessage{
+		Id:        msg.Id,
+		Sender:    "server",
+		Content:   fmt.Sprintf("recv from %s: %s", sess.RemoteAddr(), msg.Content),
+		UnixMilli: time.Now().UnixMilli(),
+	}
+	if err := sess.Send(reply); err != nil {
+		return err
+	}
+	s.sessions.Range(func(other *session.Session[*protos.ChatMessage]) bool {
+		if other != sess {
+			_ = other.TrySend(&protos.ChatMessage{
+				Id:        msg.Id,
+				Sender:    sess.ID(),
+				Content:   msg.Content,
+				UnixMilli: msg.UnixMilli,
+			})
 		}
+		return true
+	})
+	return nil
+}
 
-		reply := &protos.ChatMessage{
-			Id:        msg.Id,
-			Sender:    "server",
-			Content:   fmt.Sprintf("recv from %s: %s", clientAddr, msg.Content),
-			UnixMilli: time.Now().UnixMilli(),
-		}
-		if err := stream.Send(reply); err != nil {
-			return err
-		}
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
 
-func main() {
-	lis, err := net.Listen("tcp", "192.168.1.239:50051")
+func run() error {
+	listen := flag.String("listen", ":50051", "listen address")
+	advertise := flag.String("advertise", "127.0.0.1:50051", "address registered for clients")
+	id := flag.String("id", "chat-1", "instance id")
+	endpoints := flag.String("etcd", "localhost:2379", "comma separated etcd endpoints")
+	flag.Parse()
+
+	cfg := etcd.DefaultConfig()
+	cfg.Endpoints = strings.Split(*endpoints, ",")
+	client, err := etcd.New(cfg)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	defer client.Close()
+
+	serverCfg := rpc.DefaultServerConfig()
+	serverCfg.Addr = *listen
+	serverCfg.Reflection = true
+	srv := rpc.NewServer(rpc.WithServerConfig(serverCfg))
+	sessions := session.NewManager[*protos.ChatMessage]()
+	protos.RegisterChatServiceServer(srv, &chatServer{sessions: sessions})
 
-	grpcServer := grpc.NewServer()
-	protos.RegisterChatServiceServer(grpcServer, &chatServer{})
+	// 注册到 etcd，客户端通过 zeus://chat 发现本实例；gRPC 服务就绪后才注册，排空时先注销
+	registrar := registry.NewRegistrar(client, registry.Instance{
+		ID:      *id,
+		Name:    "chat",
+		Address: *advertise,
+	}, []string{srv.ID()})
+
+	application := app.NewBaseApplication("chat")
+	if err := application.RegisterService(srv); err != nil {
+		return err
+	}
+	if err := application.RegisterService(registrar); err != nil {
+		return err
+	}
 
-	if err := grpcServer.Serve(lis); err != nil {
-		panic(err)
+	ctx := context.Background()
+	if err := application.Init(ctx); err != nil {
+		return err
 	}
+	return application.Run(ctx)
 }
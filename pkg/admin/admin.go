@@ -0,0 +1,427 @@
+// Package admin 提供运行中应用的本地运维 HTTP 服务。
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/app"
+	"github.com/lk2023060901/zeus-go/pkg/clock"
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/scheduler"
+)
+
+const (
+	// ModuleID 表示运维模块的 ID，同时也是其配置段名称。
+	ModuleID = "admin"
+	// ModuleVersion 表示运维模块的版本。
+	ModuleVersion = "1.0.0"
+)
+
+var errNilApplication = errors.New("admin: application is nil")
+
+// Application 定义运维模块读取的应用信息，*app.BaseApplication 实现了该接口。
+type Application interface {
+	// Name 返回应用名称。
+	Name() string
+	// State 返回应用的生命周期状态。
+	State() app.State
+	// Graph 返回按依赖顺序解析后的模块、服务依赖图。
+	Graph() (app.Graph, error)
+	// ComponentState 返回指定组件的生命周期状态。
+	ComponentState(id string) app.State
+}
+
+// Config 运维模块配置。
+type Config struct {
+	// Addr 表示监听地址，默认仅监听本机。
+	Addr string `yaml:"addr"`
+	// Pprof 表示是否在 /debug/pprof/ 下暴露 pprof。
+	Pprof bool `yaml:"pprof"`
+}
+
+// DefaultConfig 返回默认配置。
+func DefaultConfig() Config {
+	return Config{
+		Addr:  "127.0.0.1:6060",
+		Pprof: true,
+	}
+}
+
+// Option 运维模块选项。
+type Option func(*Module)
+
+// WithConfig 设置运维模块配置，配置文件中的 admin 配置段会在 Init 前覆盖该配置。
+func WithConfig(cfg Config) Option {
+	return func(m *Module) {
+		m.cfg = cfg
+	}
+}
+
+// WithScheduler 在 /admin/jobs 下暴露调度器的任务列表。
+func WithScheduler(s *scheduler.Scheduler) Option {
+	return func(m *Module) {
+		m.scheduler = s
+	}
+}
+
+// WithClock 在 /admin/clock 下暴露游戏时钟。
+func WithClock(c clock.GameClock) Option {
+	return func(m *Module) {
+		m.clock = c
+	}
+}
+
+// Module 以模块形式注册到应用的运维 HTTP 服务，提供以下接口：
+//
+//	/admin/app      应用状态与模块、服务依赖图
+//	/admin/loggers  已注册的 Logger 名称；PUT 按名称模式调整等级
+//	/admin/loggers/{name}  Logger 的当前等级；PUT 调整等级
+//	/admin/jobs     调度器任务列表（需 WithScheduler）
+//	/admin/clock    游戏时钟时间与偏移（需 WithClock）
+//	/admin/build    构建信息
+//	/debug/pprof/   pprof（Config.Pprof 为 true 时）
+type Module struct {
+	app       Application
+	cfg       Config
+	scheduler *scheduler.Scheduler
+	clock     clock.GameClock
+
+	mu     sync.Mutex
+	server *http.Server
+	addr   net.Addr
+}
+
+// New 创建运维模块。
+func New(a Application, opts ...Option) *Module {
+	m := &Module{
+		app: a,
+		cfg: DefaultConfig(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// ID 返回模块 ID。
+func (m *Module) ID() string {
+	return ModuleID
+}
+
+// Version 返回模块版本。
+func (m *Module) Version() string {
+	return ModuleVersion
+}
+
+// Requires 返回依赖的模块列表，运维模块不依赖其他模块。
+func (m *Module) Requires() []string {
+	return nil
+}
+
+// Configure 从 admin 配置段读取配置。
+func (m *Module) Configure(dec app.ConfigDecoder) error {
+	return dec.Decode(&m.cfg)
+}
+
+// Init 校验模块配置。
+func (m *Module) Init(_ context.Context) error {
+	if m.app == nil {
+		return errNilApplication
+	}
+	return nil
+}
+
+// Start 在配置的地址上启动运维 HTTP 服务。
+func (m *Module) Start(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.server != nil {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", m.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           m.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	m.server = srv
+	m.addr = lis.Addr()
+	conc.Go(func() (struct{}, error) {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Get("app").Error("admin server stopped",
+				logger.Field{Key: "addr", Value: lis.Addr().String()},
+				logger.Field{Key: "error", Value: err},
+			)
+			return struct{}{}, err
+		}
+		return struct{}{}, nil
+	})
+	return nil
+}
+
+// Stop 关闭运维 HTTP 服务。
+func (m *Module) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	srv := m.server
+	m.server = nil
+	m.addr = nil
+	m.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+// Addr 返回实际监听地址，未启动时返回 nil。
+func (m *Module) Addr() net.Addr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addr
+}
+
+// Handler 返回运维接口的 HTTP 处理器，便于挂载到已有的 HTTP 服务。
+func (m *Module) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/app", m.handleApp)
+	mux.HandleFunc("GET /admin/loggers", m.handleLoggers)
+	mux.HandleFunc("PUT /admin/loggers", m.handleSetLevels)
+	mux.HandleFunc("GET /admin/loggers/{name}", m.handleLoggerLevel)
+	mux.HandleFunc("PUT /admin/loggers/{name}", m.handleSetLoggerLevel)
+	mux.HandleFunc("GET /admin/jobs", m.handleJobs)
+	mux.HandleFunc("GET /admin/clock", m.handleClock)
+	mux.HandleFunc("GET /admin/build", m.handleBuild)
+	if m.cfg.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	return mux
+}
+
+// AppInfo 表示 /admin/app 的响应。
+type AppInfo struct {
+	Name       string          `json:"name"`
+	State      string          `json:"state"`
+	Components []ComponentInfo `json:"components"`
+}
+
+// ComponentInfo 表示单个模块或服务的信息。
+type ComponentInfo struct {
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind"`
+	Version  string          `json:"version,omitempty"`
+	State    string          `json:"state"`
+	Requires []app.GraphEdge `json:"requires,omitempty"`
+}
+
+// LoggerLevel 表示 /admin/loggers/{name} 的请求与响应。
+type LoggerLevel struct {
+	Name  string `json:"name,omitempty"`
+	Level string `json:"level"`
+}
+
+// LevelPattern 表示 PUT /admin/loggers 的请求。
+type LevelPattern struct {
+	Pattern string `json:"pattern"`
+	Level   string `json:"level"`
+}
+
+// JobInfo 表示 /admin/jobs 中的单个任务。
+type JobInfo struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Spec      string    `json:"spec"`
+	Running   bool      `json:"running"`
+	LastRun   time.Time `json:"last_run"`
+	NextRun   time.Time `json:"next_run"`
+	RunCount  int64     `json:"run_count"`
+	FailCount int64     `json:"fail_count"`
+}
+
+// ClockInfo 表示 /admin/clock 的响应。
+type ClockInfo struct {
+	Now       time.Time `json:"now"`
+	RealNow   time.Time `json:"real_now"`
+	Offset    string    `json:"offset"`
+	NextReset time.Time `json:"next_reset"`
+}
+
+// BuildInfo 表示 /admin/build 的响应。
+type BuildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+}
+
+func (m *Module) handleApp(w http.ResponseWriter, _ *http.Request) {
+	graph, err := m.app.Graph()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	info := AppInfo{
+		Name:       m.app.Name(),
+		State:      m.app.State().String(),
+		Components: make([]ComponentInfo, 0, len(graph)),
+	}
+	for _, node := range graph {
+		info.Components = append(info.Components, ComponentInfo{
+			ID:       node.ID,
+			Kind:     node.Kind,
+			Version:  node.Version,
+			State:    m.app.ComponentState(node.ID).String(),
+			Requires: node.Requires,
+		})
+	}
+	writeJSON(w, info)
+}
+
+func (m *Module) handleLoggers(w http.ResponseWriter, _ *http.Request) {
+	names := logger.Names()
+	sort.Strings(names)
+	writeJSON(w, names)
+}
+
+func (m *Module) handleLoggerLevel(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	level, err := logger.GetLevel(name)
+	if err != nil {
+		writeError(w, levelErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, LoggerLevel{Name: name, Level: level.String()})
+}
+
+func (m *Module) handleSetLoggerLevel(w http.ResponseWriter, r *http.Request) {
+	var req LoggerLevel
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	name := r.PathValue("name")
+	if err := logger.SetLevel(name, level); err != nil {
+		writeError(w, levelErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, LoggerLevel{Name: name, Level: level.String()})
+}
+
+func (m *Module) handleSetLevels(w http.ResponseWriter, r *http.Request) {
+	var req LevelPattern
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	names, err := logger.SetLevels(req.Pattern, level)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if names == nil {
+		names = []string{}
+	}
+	writeJSON(w, names)
+}
+
+func levelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, logger.ErrLoggerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, logger.ErrLevelUnsupported):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (m *Module) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if m.scheduler == nil {
+		http.NotFound(w, r)
+		return
+	}
+	jobs := m.scheduler.ListJobs()
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID < jobs[j].ID
+	})
+	result := make([]JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, JobInfo{
+			ID:        int(job.ID),
+			Name:      job.Name,
+			Spec:      job.Spec,
+			Running:   job.Running,
+			LastRun:   job.LastRun,
+			NextRun:   job.NextRun,
+			RunCount:  job.RunCount,
+			FailCount: job.FailCount,
+		})
+	}
+	writeJSON(w, result)
+}
+
+func (m *Module) handleClock(w http.ResponseWriter, r *http.Request) {
+	if m.clock == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, ClockInfo{
+		Now:       m.clock.Now(),
+		RealNow:   m.clock.RealNow(),
+		Offset:    m.clock.Offset().String(),
+		NextReset: m.clock.NextResetTime(),
+	})
+}
+
+func (m *Module) handleBuild(w http.ResponseWriter, _ *http.Request) {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Path = bi.Main.Path
+		info.Version = bi.Main.Version
+		info.Settings = make(map[string]string, len(bi.Settings))
+		for _, s := range bi.Settings {
+			info.Settings[s.Key] = s.Value
+		}
+	}
+	writeJSON(w, info)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
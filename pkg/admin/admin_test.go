@@ -0,0 +1,161 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lk2023060901/zeus-go/pkg/app"
+	"github.com/lk2023060901/zeus-go/pkg/clock"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/scheduler"
+)
+
+// fakeService 是不依赖外部资源的服务。
+type fakeService struct {
+	id       string
+	requires []string
+}
+
+func (s *fakeService) ID() string                    { return s.id }
+func (s *fakeService) Requires() []string            { return s.requires }
+func (s *fakeService) Init(_ context.Context) error  { return nil }
+func (s *fakeService) Start(_ context.Context) error { return nil }
+func (s *fakeService) Stop(_ context.Context) error  { return nil }
+
+func getJSON(t *testing.T, h http.Handler, path string, out any) int {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	if out != nil && rr.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), out))
+	}
+	return rr.Code
+}
+
+func TestHandler(t *testing.T) {
+	sched, err := scheduler.New(scheduler.DefaultConfig())
+	require.NoError(t, err)
+	defer sched.Release()
+	_, err = sched.AddFunc("daily-reset", "0 5 * * *", func() error { return nil })
+	require.NoError(t, err)
+
+	gc := clock.MustNew(clock.DefaultConfig())
+	gc.SetOffset(2 * time.Hour)
+
+	a := app.NewBaseApplication("game")
+	m := New(a, WithScheduler(sched), WithClock(gc), WithConfig(Config{Addr: "127.0.0.1:0", Pprof: true}))
+	require.NoError(t, a.RegisterModule(m))
+	require.NoError(t, a.RegisterService(&fakeService{id: "gateway", requires: []string{"admin>=1.0"}}))
+	require.NoError(t, a.Start(context.Background()))
+	defer func() { require.NoError(t, a.Stop(context.Background())) }()
+
+	h := m.Handler()
+
+	var info AppInfo
+	require.Equal(t, http.StatusOK, getJSON(t, h, "/admin/app", &info))
+	assert.Equal(t, "game", info.Name)
+	assert.Equal(t, "running", info.State)
+	require.Len(t, info.Components, 2)
+	assert.Equal(t, ComponentInfo{ID: "admin", Kind: app.KindModule, Version: ModuleVersion, State: "running"}, info.Components[0])
+	assert.Equal(t, "gateway", info.Components[1].ID)
+	assert.Equal(t, app.KindService, info.Components[1].Kind)
+	assert.Equal(t, []app.GraphEdge{{ID: "admin", Constraint: ">=1.0", Version: ModuleVersion}}, info.Components[1].Requires)
+
+	var jobs []JobInfo
+	require.Equal(t, http.StatusOK, getJSON(t, h, "/admin/jobs", &jobs))
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "daily-reset", jobs[0].Name)
+	assert.Equal(t, "0 5 * * *", jobs[0].Spec)
+
+	var clk ClockInfo
+	require.Equal(t, http.StatusOK, getJSON(t, h, "/admin/clock", &clk))
+	assert.Equal(t, "2h0m0s", clk.Offset)
+	assert.WithinDuration(t, clk.RealNow.Add(2*time.Hour), clk.Now, time.Second)
+
+	var names []string
+	assert.Equal(t, http.StatusOK, getJSON(t, h, "/admin/loggers", &names))
+
+	var build BuildInfo
+	require.Equal(t, http.StatusOK, getJSON(t, h, "/admin/build", &build))
+	assert.NotEmpty(t, build.GoVersion)
+
+	assert.Equal(t, http.StatusOK, getJSON(t, h, "/debug/pprof/", nil))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/app", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func putJSON(t *testing.T, h http.Handler, path, body string, out any) int {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+	if out != nil && rr.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), out))
+	}
+	return rr.Code
+}
+
+func TestLoggerLevels(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, logger.InitFromConfig(logger.Config{Loggers: []logger.NamedConfig{
+		{Name: "admin-test.a", Filepath: filepath.Join(dir, "a.log"), Level: "info"},
+		{Name: "admin-test.b", Filepath: filepath.Join(dir, "b.log"), Level: "info"},
+	}}))
+	h := New(app.NewBaseApplication("game")).Handler()
+
+	var level LoggerLevel
+	require.Equal(t, http.StatusOK, getJSON(t, h, "/admin/loggers/admin-test.a", &level))
+	assert.Equal(t, LoggerLevel{Name: "admin-test.a", Level: "info"}, level)
+
+	require.Equal(t, http.StatusOK, putJSON(t, h, "/admin/loggers/admin-test.a", `{"level":"debug"}`, &level))
+	assert.Equal(t, "debug", level.Level)
+	got, err := logger.GetLevel("admin-test.a")
+	require.NoError(t, err)
+	assert.Equal(t, logger.LevelDebug, got)
+
+	var names []string
+	require.Equal(t, http.StatusOK, putJSON(t, h, "/admin/loggers", `{"pattern":"admin-test.*","level":"error"}`, &names))
+	assert.Equal(t, []string{"admin-test.a", "admin-test.b"}, names)
+	got, err = logger.GetLevel("admin-test.b")
+	require.NoError(t, err)
+	assert.Equal(t, logger.LevelError, got)
+	logger.ResetLevelPatterns()
+
+	assert.Equal(t, http.StatusNotFound, getJSON(t, h, "/admin/loggers/missing", nil))
+	assert.Equal(t, http.StatusNotFound, putJSON(t, h, "/admin/loggers/missing", `{"level":"debug"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, putJSON(t, h, "/admin/loggers/admin-test.a", `{"level":"verbose"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, putJSON(t, h, "/admin/loggers", `{"pattern":"[","level":"debug"}`, nil))
+}
+
+func TestOptionalEndpoints(t *testing.T) {
+	a := app.NewBaseApplication("game")
+	h := New(a, WithConfig(Config{})).Handler()
+	assert.Equal(t, http.StatusNotFound, getJSON(t, h, "/admin/jobs", nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, h, "/admin/clock", nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, h, "/debug/pprof/", nil))
+}
+
+func TestServer(t *testing.T) {
+	a := app.NewBaseApplication("game")
+	m := New(a, WithConfig(Config{Addr: "127.0.0.1:0"}))
+	require.NoError(t, a.RegisterModule(m))
+	require.NoError(t, a.Start(context.Background()))
+
+	resp, err := http.Get("http://" + m.Addr().String() + "/admin/app")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, a.Stop(context.Background()))
+	assert.Nil(t, m.Addr())
+}
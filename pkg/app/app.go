@@ -3,11 +3,14 @@ package app
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/lk2023060901/zeus-go/pkg/conc"
 	"github.com/lk2023060901/zeus-go/pkg/logger"
 	"github.com/lk2023060901/zeus-go/pkg/module"
 	"github.com/lk2023060901/zeus-go/pkg/service"
@@ -19,6 +22,7 @@ var (
 	errRegisterLocked   = errors.New("app: register is locked after init")
 	errApplicationAlive = errors.New("app: application already started")
 	errConfigLocked     = errors.New("app: config is locked after init")
+	errTransitioning    = errors.New("app: lifecycle transition in progress")
 )
 
 // Application 定义应用的生命周期与注册入口。
@@ -32,19 +36,19 @@ type Application interface {
 	// RegisterService 注册一个服务，供应用统一管理其生命周期。
 	RegisterService(s service.Service) error
 
-	// Init 初始化应用及其所有模块、服务。
+	// Init 按依赖顺序初始化应用及其所有模块、服务。
 	Init(ctx context.Context) error
 
-	// Start 启动应用及其所有模块、服务。
+	// Start 按依赖顺序启动应用及其所有模块、服务。
 	Start(ctx context.Context) error
 
 	// Run 启动应用并阻塞运行，直到收到退出信号或上下文取消。
 	Run(ctx context.Context) error
 
-	// Shutdown 触发应用的优雅关闭流程。
+	// Shutdown 触发应用的优雅关闭流程：先排空进行中的工作，再停止所有模块、服务。
 	Shutdown(ctx context.Context) error
 
-	// Stop 停止应用及其所有模块、服务。
+	// Stop 按依赖的逆序停止应用及其所有模块、服务。
 	Stop(ctx context.Context) error
 
 	// Modules 返回已注册的模块列表。
@@ -52,6 +56,9 @@ type Application interface {
 
 	// Services 返回已注册的服务列表。
 	Services() []service.Service
+
+	// State 返回应用当前的生命周期状态。
+	State() State
 }
 
 // BaseApplication 提供 Application 的基础实现。
@@ -61,10 +68,34 @@ type BaseApplication struct {
 	mu           sync.RWMutex
 	modules      []module.Module
 	services     []service.Service
+	order        []component
+	opts         options
 	configPath   string
+	overrides    []string
+	config       Config
 	initializing bool
 	initialized  bool
 	started      bool
+	state        State
+	hooks        []Hooks
+	listeners    []StateListener
+
+	componentStates map[string]State
+
+	superviseCancel context.CancelFunc
+	superviseWG     sync.WaitGroup
+	failure         error
+
+	// abandoned 记录各组件超时后仍在执行的生命周期调用
+	abandonedMu sync.Mutex
+	abandoned   map[string]*conc.Future[struct{}]
+
+	healthChecks []namedCheck
+	healthServer *http.Server
+
+	reloadMu      sync.Mutex
+	configModTime time.Time
+	unwatch       func()
 
 	shutdownOnce sync.Once
 	shutdownCh   chan struct{}
@@ -72,11 +103,16 @@ type BaseApplication struct {
 }
 
 // NewBaseApplication 创建一个基础应用实例。
-func NewBaseApplication(name string) *BaseApplication {
-	return &BaseApplication{
+func NewBaseApplication(name string, opts ...Option) *BaseApplication {
+	a := &BaseApplication{
 		name:       name,
+		opts:       defaultOptions(),
 		shutdownCh: make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(&a.opts)
+	}
+	return a
 }
 
 // Name 返回应用名称，用于标识当前应用实例。
@@ -123,7 +159,14 @@ func (a *BaseApplication) SetConfigPath(path string) error {
 	return nil
 }
 
-// Init 初始化应用及其所有模块、服务。
+// Config 返回 Init 阶段加载的应用配置。
+func (a *BaseApplication) Config() Config {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config
+}
+
+// Init 按依赖顺序初始化应用及其所有模块、服务，互不依赖的组件并发初始化。
 func (a *BaseApplication) Init(ctx context.Context) error {
 	a.mu.Lock()
 	if a.initialized {
@@ -135,43 +178,64 @@ func (a *BaseApplication) Init(ctx context.Context) error {
 		return errApplicationAlive
 	}
 	a.initializing = true
-	modules := append([]module.Module(nil), a.modules...)
-	services := append([]service.Service(nil), a.services...)
-	configPath := a.configPath
+	from := a.swapState(StateInitializing)
+	components := collectComponents(a.modules, a.services)
+	src := a.configSource()
 	a.mu.Unlock()
+	a.notifyState(from, StateInitializing)
 
-	if err := a.initLogger(configPath); err != nil {
-		a.mu.Lock()
-		a.initializing = false
-		a.mu.Unlock()
+	order, err := sortComponents(components)
+	if err != nil {
+		a.abortInit()
 		return err
 	}
 
-	for _, m := range modules {
-		if err := m.Init(ctx); err != nil {
-			a.mu.Lock()
-			a.initializing = false
-			a.mu.Unlock()
-			return err
-		}
+	modTime := configFileModTime(src.Path)
+	cfg, err := LoadConfig(src)
+	if err != nil {
+		a.abortInit()
+		return err
 	}
-	for _, s := range services {
-		if err := s.Init(ctx); err != nil {
-			a.mu.Lock()
-			a.initializing = false
-			a.mu.Unlock()
-			return err
-		}
+	a.markConfigFile(modTime)
+	if err := a.initLoggerFromConfig(cfg); err != nil {
+		a.abortInit()
+		return err
+	}
+	a.mu.Lock()
+	a.config = cfg
+	a.mu.Unlock()
+
+	var lifecycleErr LifecycleError
+	a.record(ctx, &lifecycleErr, PhaseConfigure, order, configure(order, cfg))
+	if err := lifecycleErr.err(); err != nil {
+		a.abortInit()
+		return err
+	}
+
+	if err := a.runHooks(ctx, "before init", func(h Hooks) func(context.Context) error {
+		return h.BeforeInit
+	}); err != nil {
+		a.abortInit()
+		return err
+	}
+
+	a.record(ctx, &lifecycleErr, PhaseInit, order, a.forward(ctx, PhaseInit, order, component.Init))
+	if err := lifecycleErr.err(); err != nil {
+		a.abortInit()
+		return err
 	}
 
 	a.mu.Lock()
+	a.order = order
 	a.initializing = false
 	a.initialized = true
 	a.mu.Unlock()
+	a.setState(StateInitialized)
 	return nil
 }
 
-// Start 启动应用及其所有模块、服务。
+// Start 按依赖顺序启动应用及其所有模块、服务，互不依赖的组件并发启动；
+// 任一组件启动失败时，已启动的组件会按逆序回滚停止。
 func (a *BaseApplication) Start(ctx context.Context) error {
 	if err := a.Init(ctx); err != nil {
 		return err
@@ -182,36 +246,53 @@ func (a *BaseApplication) Start(ctx context.Context) error {
 		a.mu.Unlock()
 		return nil
 	}
-	modules := append([]module.Module(nil), a.modules...)
-	services := append([]service.Service(nil), a.services...)
+	if a.state == StateStarting || a.state == StateDraining || a.state == StateStopping {
+		a.mu.Unlock()
+		return errTransitioning
+	}
+	from := a.swapState(StateStarting)
+	order := append([]component(nil), a.order...)
 	a.mu.Unlock()
+	a.notifyState(from, StateStarting)
 
-	var startedModules []module.Module
-	for _, m := range modules {
-		if err := m.Start(ctx); err != nil {
-			a.rollbackModules(ctx, startedModules)
-			return err
-		}
-		startedModules = append(startedModules, m)
+	if err := a.startHealthServer(); err != nil {
+		a.setState(StateFailed)
+		return err
 	}
 
-	var startedServices []service.Service
-	for _, s := range services {
-		if err := s.Start(ctx); err != nil {
-			a.rollbackServices(ctx, startedServices)
-			a.rollbackModules(ctx, startedModules)
-			return err
+	var lifecycleErr LifecycleError
+	errs := a.forward(ctx, PhaseStart, order, component.Start)
+	a.record(ctx, &lifecycleErr, PhaseStart, order, errs)
+
+	var hookErr error
+	if len(lifecycleErr.Failures) == 0 {
+		hookErr = a.runHooks(ctx, "after start", func(h Hooks) func(context.Context) error {
+			return h.AfterStart
+		})
+	}
+	if len(lifecycleErr.Failures) > 0 || hookErr != nil {
+		var started []component
+		for i, c := range order {
+			if errs[i] == nil {
+				started = append(started, c)
+			}
 		}
-		startedServices = append(startedServices, s)
+		a.record(ctx, &lifecycleErr, PhaseRollback, started, a.backward(ctx, PhaseRollback, started, component.Stop))
+		_ = a.stopHealthServer(ctx)
+		a.setState(StateFailed)
+		return joinErrors(hookErr, lifecycleErr.err())
 	}
 
 	a.mu.Lock()
 	a.started = true
 	a.mu.Unlock()
+	a.setState(StateRunning)
+	a.supervise(order)
+	a.watchConfig()
 	return nil
 }
 
-// Run 启动应用并阻塞运行，直到收到退出信号或上下文取消。
+// Run 启动应用并阻塞运行，直到收到退出信号或上下文取消；收到 SIGHUP 时重新加载配置。
 func (a *BaseApplication) Run(ctx context.Context) error {
 	if err := a.Start(ctx); err != nil {
 		return err
@@ -221,57 +302,86 @@ func (a *BaseApplication) Run(ctx context.Context) error {
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	defer signal.Stop(sigCh)
 
-	select {
-	case <-ctx.Done():
-		_ = a.Shutdown(context.Background())
-		return ctx.Err()
-	case <-sigCh:
-		_ = a.Shutdown(context.Background())
-		return a.shutdownError()
-	case <-a.shutdownCh:
-		return a.shutdownError()
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = a.Shutdown(context.Background())
+			return ctx.Err()
+		case <-sigCh:
+			_ = a.Shutdown(context.Background())
+			return a.shutdownError()
+		case <-hupCh:
+			if err := a.Reload(ctx); err != nil {
+				logger.Get("app").Error("config reload failed", logger.Field{Key: "error", Value: err})
+			}
+		case <-a.shutdownCh:
+			return a.shutdownError()
+		}
 	}
 }
 
-// Shutdown 触发应用的优雅关闭流程。
+// Shutdown 触发应用的优雅关闭流程：先排空进行中的工作，再停止所有模块、服务。
+// 排空失败或超时不会中断停止流程。
 func (a *BaseApplication) Shutdown(ctx context.Context) error {
 	a.shutdownOnce.Do(func() {
+		drainErr := a.Drain(ctx)
 		err := a.Stop(ctx)
 		a.mu.Lock()
-		a.shutdownErr = err
+		a.shutdownErr = joinErrors(a.failure, drainErr, err)
 		a.mu.Unlock()
 		close(a.shutdownCh)
 	})
 	return a.shutdownError()
 }
 
-// Stop 停止应用及其所有模块、服务。
+// Stop 按依赖的逆序停止应用及其所有模块、服务，组件在其全部依赖者停止后才停止。
+// 最后写出全部已注册 Logger 的缓冲。
 func (a *BaseApplication) Stop(ctx context.Context) error {
 	a.mu.Lock()
 	if !a.started {
 		a.mu.Unlock()
 		return nil
 	}
-	services := append([]service.Service(nil), a.services...)
-	modules := append([]module.Module(nil), a.modules...)
+	if a.state == StateStopping {
+		a.mu.Unlock()
+		return errTransitioning
+	}
+	from := a.swapState(StateStopping)
+	order := append([]component(nil), a.order...)
 	a.mu.Unlock()
+	a.notifyState(from, StateStopping)
+	a.unwatchConfig()
+	a.unsupervise()
 
-	var stopErr error
-	for i := len(services) - 1; i >= 0; i-- {
-		if err := services[i].Stop(ctx); err != nil && stopErr == nil {
-			stopErr = err
-		}
-	}
-	for i := len(modules) - 1; i >= 0; i-- {
-		if err := modules[i].Stop(ctx); err != nil && stopErr == nil {
-			stopErr = err
-		}
-	}
+	beforeErr := a.runHooks(ctx, "before stop", func(h Hooks) func(context.Context) error {
+		return h.BeforeStop
+	})
+
+	var lifecycleErr LifecycleError
+	a.record(ctx, &lifecycleErr, PhaseStop, order, a.backward(ctx, PhaseStop, order, component.Stop))
 
 	a.mu.Lock()
 	a.started = false
 	a.mu.Unlock()
-	return stopErr
+
+	afterErr := a.runHooks(ctx, "after stop", func(h Hooks) func(context.Context) error {
+		return h.AfterStop
+	})
+
+	serverErr := a.stopHealthServer(ctx)
+	// 写出异步日志缓冲区，停止过程中的日志也会落盘
+	logErr := logger.CloseAll()
+
+	if err := joinErrors(beforeErr, lifecycleErr.err(), afterErr, serverErr, logErr); err != nil {
+		a.setState(StateFailed)
+		return err
+	}
+	a.setState(StateStopped)
+	return nil
 }
 
 // Modules 返回已注册的模块列表。
@@ -288,16 +398,12 @@ func (a *BaseApplication) Services() []service.Service {
 	return append([]service.Service(nil), a.services...)
 }
 
-func (a *BaseApplication) rollbackModules(ctx context.Context, modules []module.Module) {
-	for i := len(modules) - 1; i >= 0; i-- {
-		_ = modules[i].Stop(ctx)
-	}
-}
-
-func (a *BaseApplication) rollbackServices(ctx context.Context, services []service.Service) {
-	for i := len(services) - 1; i >= 0; i-- {
-		_ = services[i].Stop(ctx)
-	}
+func (a *BaseApplication) abortInit() {
+	a.mu.Lock()
+	a.initializing = false
+	from := a.swapState(StateFailed)
+	a.mu.Unlock()
+	a.notifyState(from, StateFailed)
 }
 
 func (a *BaseApplication) shutdownError() error {
@@ -306,20 +412,9 @@ func (a *BaseApplication) shutdownError() error {
 	return a.shutdownErr
 }
 
-func (a *BaseApplication) initLogger(path string) error {
-	if path != "" {
-		loaded, err := LoadConfigFromFile(path)
-		if err != nil {
-			return err
-		}
-		return a.initLoggerFromConfig(loaded)
-	}
-	return nil
-}
-
 func (a *BaseApplication) initLoggerFromConfig(cfg Config) error {
-	if len(cfg.Loggers) == 0 {
+	if len(cfg.Loggers) == 0 && len(cfg.LoggerLevels) == 0 {
 		return nil
 	}
-	return logger.InitFromConfig(logger.Config{Loggers: cfg.Loggers})
+	return logger.InitFromConfig(logger.Config{Loggers: cfg.Loggers, LoggerLevels: cfg.LoggerLevels})
 }
@@ -0,0 +1,1235 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lk2023060901/zeus-go/pkg/clock"
+	"github.com/lk2023060901/zeus-go/pkg/etcd"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/scheduler"
+)
+
+// recorder 按调用顺序记录组件生命周期事件。
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+type fakeComponent struct {
+	id       string
+	version  string
+	requires []string
+	rec      *recorder
+	startErr error
+	stopErr  error
+}
+
+func (c *fakeComponent) ID() string         { return c.id }
+func (c *fakeComponent) Requires() []string { return c.requires }
+
+func (c *fakeComponent) Version() string {
+	if c.version == "" {
+		return "1.0.0"
+	}
+	return c.version
+}
+
+func (c *fakeComponent) Init(_ context.Context) error {
+	c.rec.add("init:" + c.id)
+	return nil
+}
+
+func (c *fakeComponent) Start(_ context.Context) error {
+	if c.startErr != nil {
+		return c.startErr
+	}
+	c.rec.add("start:" + c.id)
+	return nil
+}
+
+func (c *fakeComponent) Stop(_ context.Context) error {
+	c.rec.add("stop:" + c.id)
+	return c.stopErr
+}
+
+func TestDependencyOrder(t *testing.T) {
+	rec := &recorder{}
+	a := NewBaseApplication("test")
+
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", requires: []string{"etcd", "db"}, rec: rec}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "db", rec: rec}))
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", requires: []string{"config"}, rec: rec}))
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "config", rec: rec}))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+	require.NoError(t, a.Stop(ctx))
+
+	events := rec.list()
+	require.Len(t, events, 12)
+	for _, phase := range []string{"init:", "start:"} {
+		assertBefore(t, events, phase+"config", phase+"etcd")
+		assertBefore(t, events, phase+"etcd", phase+"gateway")
+		assertBefore(t, events, phase+"db", phase+"gateway")
+	}
+	assertBefore(t, events, "init:gateway", "start:config")
+	assertBefore(t, events, "stop:gateway", "stop:etcd")
+	assertBefore(t, events, "stop:gateway", "stop:db")
+	assertBefore(t, events, "stop:etcd", "stop:config")
+}
+
+func TestParallelStart(t *testing.T) {
+	barrier := &sync.WaitGroup{}
+	barrier.Add(2)
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterService(&blockingComponent{id: "a", barrier: barrier}))
+	require.NoError(t, a.RegisterService(&blockingComponent{id: "b", barrier: barrier}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Start(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("independent components were not started concurrently")
+	}
+}
+
+// blockingComponent 在 Start 中等待所有同伴都进入 Start，用于验证并发启动。
+type blockingComponent struct {
+	id      string
+	barrier *sync.WaitGroup
+}
+
+func (c *blockingComponent) ID() string                   { return c.id }
+func (c *blockingComponent) Requires() []string           { return nil }
+func (c *blockingComponent) Init(_ context.Context) error { return nil }
+func (c *blockingComponent) Stop(_ context.Context) error { return nil }
+
+func (c *blockingComponent) Start(_ context.Context) error {
+	c.barrier.Done()
+	c.barrier.Wait()
+	return nil
+}
+
+func assertBefore(t *testing.T, events []string, first, second string) {
+	t.Helper()
+	i, j := slices.Index(events, first), slices.Index(events, second)
+	require.NotEqual(t, -1, i, first)
+	require.NotEqual(t, -1, j, second)
+	assert.Less(t, i, j, "%s should happen before %s", first, second)
+}
+
+func TestDependencyMissing(t *testing.T) {
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", requires: []string{"etcd"}, rec: &recorder{}}))
+
+	err := a.Init(context.Background())
+	assert.ErrorIs(t, err, errMissingDependency)
+	assert.Contains(t, err.Error(), `"gateway" requires "etcd"`)
+}
+
+func TestDependencyCycle(t *testing.T) {
+	a := NewBaseApplication("test")
+	rec := &recorder{}
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "a", requires: []string{"b"}, rec: rec}))
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "b", requires: []string{"c"}, rec: rec}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "c", requires: []string{"a"}, rec: rec}))
+
+	err := a.Init(context.Background())
+	assert.ErrorIs(t, err, errDependencyCycle)
+	assert.Contains(t, err.Error(), "a -> b -> c -> a")
+	assert.Empty(t, rec.list())
+}
+
+func TestDuplicateComponent(t *testing.T) {
+	a := NewBaseApplication("test")
+	rec := &recorder{}
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "etcd", rec: rec}))
+
+	err := a.Init(context.Background())
+	assert.ErrorIs(t, err, errDuplicateComponent)
+}
+
+func TestStartRollback(t *testing.T) {
+	rec := &recorder{}
+	boom := errors.New("boom")
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "db", requires: []string{"etcd"}, rec: rec}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", requires: []string{"db"}, rec: rec, startErr: boom}))
+
+	err := a.Start(context.Background())
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []string{
+		"init:etcd", "init:db", "init:gateway",
+		"start:etcd", "start:db",
+		"stop:db", "stop:etcd",
+	}, rec.list())
+}
+
+// hangingComponent 在 Stop 中忽略 ctx 并一直阻塞，模拟卡死的组件。
+type hangingComponent struct {
+	fakeComponent
+	release chan struct{}
+}
+
+func (c *hangingComponent) Stop(_ context.Context) error {
+	<-c.release
+	return nil
+}
+
+func TestStopComponentTimeout(t *testing.T) {
+	rec := &recorder{}
+	hung := &hangingComponent{
+		fakeComponent: fakeComponent{id: "hung", requires: []string{"etcd"}, rec: rec},
+		release:       make(chan struct{}),
+	}
+	defer close(hung.release)
+
+	a := NewBaseApplication("test", WithComponentTimeout("hung", Timeouts{Stop: 50 * time.Millisecond}))
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec}))
+	require.NoError(t, a.RegisterService(hung))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+
+	begin := time.Now()
+	err := a.Stop(ctx)
+	assert.Less(t, time.Since(begin), time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var lifecycleErr *LifecycleError
+	require.ErrorAs(t, err, &lifecycleErr)
+	require.Len(t, lifecycleErr.Failures, 1)
+	assert.Equal(t, "hung", lifecycleErr.Failures[0].ID)
+	assert.Equal(t, PhaseStop, lifecycleErr.Failures[0].Phase)
+	assert.Contains(t, rec.list(), "stop:etcd")
+}
+
+func TestStopPhaseTimeout(t *testing.T) {
+	hung := &hangingComponent{
+		fakeComponent: fakeComponent{id: "hung", rec: &recorder{}},
+		release:       make(chan struct{}),
+	}
+	defer close(hung.release)
+
+	a := NewBaseApplication("test", WithTimeouts(Timeouts{Stop: 50 * time.Millisecond}))
+	require.NoError(t, a.RegisterService(hung))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+	assert.ErrorIs(t, a.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestAggregatedErrors(t *testing.T) {
+	rec := &recorder{}
+	errStart := errors.New("start failed")
+	errStop := errors.New("stop failed")
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec, stopErr: errStop}))
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "db", rec: rec, stopErr: errStop}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", requires: []string{"etcd", "db"}, rec: rec, startErr: errStart}))
+
+	err := a.Start(context.Background())
+	var lifecycleErr *LifecycleError
+	require.ErrorAs(t, err, &lifecycleErr)
+	assert.ErrorIs(t, err, errStart)
+	assert.ErrorIs(t, err, errStop)
+
+	got := make([]string, 0, len(lifecycleErr.Failures))
+	for _, f := range lifecycleErr.Failures {
+		got = append(got, string(f.Phase)+":"+f.ID)
+	}
+	assert.Equal(t, []string{"start:gateway", "rollback:etcd", "rollback:db"}, got)
+}
+
+func TestHooksAndState(t *testing.T) {
+	rec := &recorder{}
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec}))
+	assert.Equal(t, StateCreated, a.State())
+
+	var states []string
+	a.AddStateListener(func(from, to State) {
+		states = append(states, from.String()+"->"+to.String())
+	})
+	a.AddHooks(Hooks{
+		BeforeInit: func(_ context.Context) error {
+			rec.add("hook:before_init")
+			return nil
+		},
+		AfterStart: func(_ context.Context) error {
+			rec.add("hook:after_start")
+			return nil
+		},
+		BeforeStop: func(_ context.Context) error {
+			rec.add("hook:before_stop")
+			return nil
+		},
+		AfterStop: func(_ context.Context) error {
+			rec.add("hook:after_stop")
+			return nil
+		},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+	assert.Equal(t, StateRunning, a.State())
+	require.NoError(t, a.Stop(ctx))
+	assert.Equal(t, StateStopped, a.State())
+
+	assert.Equal(t, []string{
+		"hook:before_init", "init:etcd",
+		"start:etcd", "hook:after_start",
+		"hook:before_stop", "stop:etcd", "hook:after_stop",
+	}, rec.list())
+	assert.Equal(t, []string{
+		"created->initializing",
+		"initializing->initialized",
+		"initialized->starting",
+		"starting->running",
+		"running->stopping",
+		"stopping->stopped",
+	}, states)
+}
+
+func TestAfterStartHookFailure(t *testing.T) {
+	rec := &recorder{}
+	errRegister := errors.New("register failed")
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", rec: rec}))
+	a.AddHooks(Hooks{
+		AfterStart: func(_ context.Context) error {
+			return errRegister
+		},
+	})
+
+	err := a.Start(context.Background())
+	assert.ErrorIs(t, err, errRegister)
+	assert.Equal(t, StateFailed, a.State())
+	assert.Equal(t, []string{"init:gateway", "start:gateway", "stop:gateway"}, rec.list())
+}
+
+func TestComponentFailedHook(t *testing.T) {
+	boom := errors.New("boom")
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", rec: &recorder{}, startErr: boom}))
+
+	var failed []*ComponentError
+	a.AddHooks(Hooks{
+		OnComponentFailed: func(_ context.Context, err *ComponentError) {
+			failed = append(failed, err)
+		},
+	})
+
+	assert.ErrorIs(t, a.Start(context.Background()), boom)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "gateway", failed[0].ID)
+	assert.Equal(t, PhaseStart, failed[0].Phase)
+	assert.Equal(t, StateFailed, a.State())
+}
+
+// supervisedComponent 通过 failures 通道上报运行期故障。
+type supervisedComponent struct {
+	fakeComponent
+	failures chan error
+}
+
+func (c *supervisedComponent) Failures() <-chan error {
+	return c.failures
+}
+
+func (c *supervisedComponent) count(event string) int {
+	n := 0
+	for _, e := range c.rec.list() {
+		if e == event {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSupervisorRestart(t *testing.T) {
+	svc := &supervisedComponent{
+		fakeComponent: fakeComponent{id: "gateway", rec: &recorder{}},
+		failures:      make(chan error, 1),
+	}
+	a := NewBaseApplication("test", WithSupervisor(SupervisorConfig{
+		MaxRestarts: 2,
+		Backoff: scheduler.JobOptions{
+			BackoffStrategy: scheduler.BackoffFixed,
+			InitialBackoff:  time.Millisecond,
+		},
+	}))
+	require.NoError(t, a.RegisterService(svc))
+
+	var failedPhases []Phase
+	var mu sync.Mutex
+	a.AddHooks(Hooks{
+		OnComponentFailed: func(_ context.Context, err *ComponentError) {
+			mu.Lock()
+			failedPhases = append(failedPhases, err.Phase)
+			mu.Unlock()
+		},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+
+	svc.failures <- errors.New("upstream lost")
+	assert.Eventually(t, func() bool {
+		return svc.count("start:gateway") == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, StateRunning, a.State())
+
+	require.NoError(t, a.Stop(ctx))
+	mu.Lock()
+	assert.Equal(t, []Phase{PhaseRun}, failedPhases)
+	mu.Unlock()
+}
+
+func TestSupervisorEscalation(t *testing.T) {
+	svc := &supervisedComponent{
+		fakeComponent: fakeComponent{id: "gateway", rec: &recorder{}},
+		failures:      make(chan error, 1),
+	}
+	a := NewBaseApplication("test", WithSupervisor(SupervisorConfig{MaxRestarts: 1}))
+	require.NoError(t, a.RegisterService(svc))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+
+	upstream := errors.New("upstream lost")
+	svc.failures <- upstream
+	assert.Eventually(t, func() bool {
+		return svc.count("start:gateway") == 2
+	}, time.Second, time.Millisecond)
+	svc.failures <- upstream
+
+	select {
+	case <-a.shutdownCh:
+	case <-time.After(time.Second):
+		t.Fatal("supervisor did not escalate to shutdown")
+	}
+	assert.ErrorIs(t, a.shutdownError(), upstream)
+	assert.Equal(t, StateStopped, a.State())
+}
+
+// slowRestartComponent 的重启 Start 在 ctx 取消后才返回。
+type slowRestartComponent struct {
+	supervisedComponent
+	restarting chan struct{}
+	starts     atomic.Int32
+}
+
+func (c *slowRestartComponent) Start(ctx context.Context) error {
+	if c.starts.Add(1) > 1 {
+		close(c.restarting)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		c.rec.add("restart-returned:" + c.id)
+		return ctx.Err()
+	}
+	return c.fakeComponent.Start(ctx)
+}
+
+func TestSupervisorStopWaitsForRestart(t *testing.T) {
+	svc := &slowRestartComponent{
+		supervisedComponent: supervisedComponent{
+			fakeComponent: fakeComponent{id: "gateway", rec: &recorder{}},
+			failures:      make(chan error, 1),
+		},
+		restarting: make(chan struct{}),
+	}
+	a := NewBaseApplication("test", WithSupervisor(SupervisorConfig{MaxRestarts: 1}))
+	require.NoError(t, a.RegisterService(svc))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+	svc.failures <- errors.New("upstream lost")
+	<-svc.restarting
+
+	// 停止时先等待进行中的重启返回，再停止服务
+	require.NoError(t, a.Stop(ctx))
+	events := svc.rec.list()
+	assert.Equal(t, []string{"restart-returned:gateway", "stop:gateway"}, events[len(events)-2:])
+}
+
+func TestSupervisorResetAfter(t *testing.T) {
+	svc := &supervisedComponent{
+		fakeComponent: fakeComponent{id: "gateway", rec: &recorder{}},
+		failures:      make(chan error, 1),
+	}
+	a := NewBaseApplication("test", WithSupervisor(SupervisorConfig{
+		MaxRestarts: 1,
+		ResetAfter:  20 * time.Millisecond,
+	}))
+	require.NoError(t, a.RegisterService(svc))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+
+	// 故障间隔超过 ResetAfter 时重启次数被重置，应用保持运行
+	for i := 2; i <= 4; i++ {
+		time.Sleep(30 * time.Millisecond)
+		svc.failures <- errors.New("upstream lost")
+		assert.Eventually(t, func() bool {
+			return svc.count("start:gateway") == i
+		}, time.Second, time.Millisecond)
+	}
+	assert.Equal(t, StateRunning, a.State())
+	assert.NoError(t, a.shutdownError())
+	require.NoError(t, a.Stop(ctx))
+}
+
+// healthComponent 同时实现就绪与存活检查。
+type healthComponent struct {
+	fakeComponent
+	healthErr error
+}
+
+func (c *healthComponent) HealthCheck(_ context.Context) error {
+	return c.healthErr
+}
+
+func (c *healthComponent) LivenessCheck(_ context.Context) error {
+	return nil
+}
+
+type healthFunc func(ctx context.Context) error
+
+func (f healthFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestHealthReports(t *testing.T) {
+	svc := &healthComponent{fakeComponent: fakeComponent{id: "gateway", rec: &recorder{}}}
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterService(svc))
+	require.NoError(t, a.AddHealthCheck("etcd", healthFunc(func(_ context.Context) error {
+		return nil
+	})))
+
+	ctx := context.Background()
+	assert.True(t, a.Liveness(ctx).Healthy())
+	assert.False(t, a.Readiness(ctx).Healthy())
+
+	require.NoError(t, a.Start(ctx))
+	report := a.Readiness(ctx)
+	assert.True(t, report.Healthy())
+	assert.Equal(t, "running", report.State)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "gateway", report.Checks[0].Name)
+	assert.Equal(t, "etcd", report.Checks[1].Name)
+
+	svc.healthErr = errors.New("upstream lost")
+	report = a.Readiness(ctx)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, HealthDown, report.Checks[0].Status)
+	assert.Equal(t, "upstream lost", report.Checks[0].Error)
+	assert.True(t, a.Liveness(ctx).Healthy())
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	a := NewBaseApplication("test", WithHealthCheckTimeout(20*time.Millisecond))
+	require.NoError(t, a.AddHealthCheck("etcd", healthFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return nil
+	})))
+
+	begin := time.Now()
+	report := a.Readiness(context.Background())
+	assert.Less(t, time.Since(begin), 500*time.Millisecond)
+	assert.False(t, report.Healthy())
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "etcd", report.Checks[0].Name)
+	assert.Equal(t, HealthDown, report.Checks[0].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestHealthHandler(t *testing.T) {
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", rec: &recorder{}}))
+	handler := a.HealthHandler()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	require.NoError(t, a.Start(context.Background()))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, HealthUp, report.Status)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+// configurableComponent 从同名配置段解码 etcd 配置。
+type configurableComponent struct {
+	fakeComponent
+	cfg *etcd.Config
+}
+
+func (c *configurableComponent) Configure(dec ConfigDecoder) error {
+	return dec.Decode(c.cfg)
+}
+
+func TestConfigSections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+etcd:
+  endpoints: ["10.0.0.1:2379", "10.0.0.2:2379"]
+  dial_timeout: 3s
+scheduler:
+  timezone: UTC
+  with_seconds: true
+  default_job_options:
+    max_retries: 5
+    backoff_strategy: fixed
+clock:
+  reset_hour: 4
+`), 0o644))
+
+	etcdModule := &configurableComponent{
+		fakeComponent: fakeComponent{id: "etcd", rec: &recorder{}},
+		cfg:           etcd.DefaultConfig(),
+	}
+	missing := &configurableComponent{
+		fakeComponent: fakeComponent{id: "missing", rec: &recorder{}},
+		cfg:           etcd.DefaultConfig(),
+	}
+	a := NewBaseApplication("test")
+	require.NoError(t, a.SetConfigPath(path))
+	require.NoError(t, a.RegisterModule(etcdModule))
+	require.NoError(t, a.RegisterModule(missing))
+	require.NoError(t, a.Init(context.Background()))
+
+	assert.Equal(t, []string{"10.0.0.1:2379", "10.0.0.2:2379"}, etcdModule.cfg.Endpoints)
+	assert.Equal(t, 3*time.Second, etcdModule.cfg.DialTimeout)
+	assert.Equal(t, 3, etcdModule.cfg.MaxRetries)
+	assert.Equal(t, etcd.DefaultConfig(), missing.cfg)
+
+	cfg := a.Config()
+	schedCfg := scheduler.DefaultConfig()
+	require.NoError(t, cfg.Decode("scheduler", schedCfg))
+	assert.Equal(t, "UTC", schedCfg.Timezone)
+	assert.True(t, schedCfg.WithSeconds)
+	assert.Equal(t, 5, schedCfg.DefaultJobOptions.MaxRetries)
+	assert.Equal(t, scheduler.BackoffFixed, schedCfg.DefaultJobOptions.BackoffStrategy)
+	assert.True(t, schedCfg.Middleware.Recovery)
+
+	clockCfg := clock.DefaultConfig()
+	require.NoError(t, cfg.Decode("clock", &clockCfg))
+	assert.Equal(t, 4, clockCfg.ResetHour)
+	assert.Equal(t, "Asia/Shanghai", clockCfg.Timezone)
+}
+
+func TestConfigureFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("etcd:\n  dial_timeout: soon\n"), 0o644))
+
+	rec := &recorder{}
+	a := NewBaseApplication("test")
+	require.NoError(t, a.SetConfigPath(path))
+	require.NoError(t, a.RegisterModule(&configurableComponent{
+		fakeComponent: fakeComponent{id: "etcd", rec: rec},
+		cfg:           etcd.DefaultConfig(),
+	}))
+
+	err := a.Init(context.Background())
+	var lifecycleErr *LifecycleError
+	require.ErrorAs(t, err, &lifecycleErr)
+	assert.Equal(t, PhaseConfigure, lifecycleErr.Failures[0].Phase)
+	assert.Empty(t, rec.list())
+}
+
+func TestLayeredConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+loggers:
+  - name: ws
+    filepath: ${LOG_DIR:-/var/log}/ws.log
+    level: info
+etcd:
+  endpoints: ["${ETCD_HOST}:2379"]
+  dial_timeout: 5s
+  max_retries: 3
+`), 0o644))
+
+	t.Setenv("ETCD_HOST", "etcd-0")
+	t.Setenv("ZEUS_ETCD_DIAL_TIMEOUT", "2s")
+	t.Setenv("ZEUS_ETCD_MAX_RETRIES", "7")
+	t.Setenv("ZEUS_LOGGERS_WS_LEVEL", "debug")
+	t.Setenv("ZEUS_CLOCK_RESET_HOUR", "6")
+
+	cfg, err := LoadConfig(ConfigSource{
+		Path:      path,
+		EnvPrefix: "ZEUS",
+		Overrides: []string{"etcd.max_retries=9", "etcd.endpoints=a:2379,b:2379"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Loggers, 1)
+	assert.Equal(t, "/var/log/ws.log", cfg.Loggers[0].Filepath)
+	assert.Equal(t, "debug", cfg.Loggers[0].Level)
+
+	etcdCfg := etcd.DefaultConfig()
+	require.NoError(t, cfg.Decode("etcd", etcdCfg))
+	assert.Equal(t, []string{"a:2379", "b:2379"}, etcdCfg.Endpoints)
+	assert.Equal(t, 2*time.Second, etcdCfg.DialTimeout)
+	assert.Equal(t, 9, etcdCfg.MaxRetries)
+
+	clockCfg := clock.DefaultConfig()
+	require.NoError(t, cfg.Decode("clock", &clockCfg))
+	assert.Equal(t, 6, clockCfg.ResetHour)
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("ETCD_HOST", "etcd-0")
+	assert.Equal(t, "etcd-0:2379", interpolate("${ETCD_HOST}:2379"))
+	assert.Equal(t, "fallback", interpolate("${ZEUS_UNSET_VAR:-fallback}"))
+	assert.Equal(t, "", interpolate("${ZEUS_UNSET_VAR}"))
+
+	// 变量值只替换标量内容，不会注入新的键
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("etcd:\n  username: ${ETCD_USER}\n  max_retries: ${ETCD_RETRIES:-4}\n"), 0o644))
+	t.Setenv("ETCD_USER", "root\npassword: injected")
+	cfg, err := LoadConfig(ConfigSource{Path: path})
+	require.NoError(t, err)
+	etcdCfg := etcd.DefaultConfig()
+	require.NoError(t, cfg.Decode("etcd", etcdCfg))
+	assert.Equal(t, "root\npassword: injected", etcdCfg.Username)
+	assert.Empty(t, etcdCfg.Password)
+	assert.Equal(t, 4, etcdCfg.MaxRetries)
+}
+
+func TestEnvListWithoutKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("etcd:\n  dial_timeout: 5s\n"), 0o644))
+	t.Setenv("ZEUS_ETCD_ENDPOINTS", "a:2379, b:2379")
+
+	cfg, err := LoadConfig(ConfigSource{Path: path, EnvPrefix: "ZEUS"})
+	require.NoError(t, err)
+	etcdCfg := etcd.DefaultConfig()
+	require.NoError(t, cfg.Decode("etcd", etcdCfg))
+	assert.Equal(t, []string{"a:2379", "b:2379"}, etcdCfg.Endpoints)
+
+	// 单个值同样解码为列表，原配置树保持不变
+	require.NoError(t, cfg.Decode("etcd", etcdCfg))
+	assert.Equal(t, yaml.ScalarNode, cfg.Sections["etcd"].Content[3].Kind)
+	cfg, err = LoadConfig(ConfigSource{Overrides: []string{"etcd.endpoints=c:2379"}})
+	require.NoError(t, err)
+	require.NoError(t, cfg.Decode("etcd", etcdCfg))
+	assert.Equal(t, []string{"c:2379"}, etcdCfg.Endpoints)
+}
+
+func TestRegisterFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("etcd:\n  endpoints: [\"a:2379\"]\n"), 0o644))
+
+	a := NewBaseApplication("test")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	a.RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"-config", path, "-set", "etcd.endpoints=b:2379", "-set", "etcd.username=root"}))
+	require.NoError(t, a.Init(context.Background()))
+
+	etcdCfg := etcd.DefaultConfig()
+	require.NoError(t, a.Config().Decode("etcd", etcdCfg))
+	assert.Equal(t, []string{"b:2379"}, etcdCfg.Endpoints)
+	assert.Equal(t, "root", etcdCfg.Username)
+
+	assert.Error(t, fs.Parse([]string{"-set", "etcd.username=admin"}))
+	assert.Error(t, applyOverride(&yaml.Node{Kind: yaml.MappingNode}, "missing-value"))
+}
+
+// reloadableScheduler 记录调度器服务收到的配置变更。
+type reloadableScheduler struct {
+	*SchedulerService
+	changes []ConfigChange
+}
+
+func (c *reloadableScheduler) Reload(ctx context.Context, change ConfigChange) error {
+	c.changes = append(c.changes, change)
+	return c.SchedulerService.Reload(ctx, change)
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	write := func(level string, retries int) {
+		raw, err := yaml.Marshal(map[string]any{
+			"loggers": []map[string]any{{
+				"name":     "reload-test",
+				"filepath": filepath.Join(dir, "reload.log"),
+				"level":    level,
+			}},
+			"scheduler": map[string]any{
+				"default_job_options": map[string]any{"max_retries": retries},
+			},
+			"etcd": map[string]any{"endpoints": []string{"a:2379"}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, raw, 0o644))
+	}
+	write("info", 1)
+
+	sched, err := scheduler.New(scheduler.DefaultConfig())
+	require.NoError(t, err)
+	reloadable := &reloadableScheduler{SchedulerService: NewSchedulerService(sched)}
+	etcdModule := &configurableComponent{
+		fakeComponent: fakeComponent{id: "etcd", rec: &recorder{}},
+		cfg:           etcd.DefaultConfig(),
+	}
+
+	a := NewBaseApplication("test")
+	assert.ErrorIs(t, a.Reload(context.Background()), errNotInitialized)
+	require.NoError(t, a.SetConfigPath(path))
+	require.NoError(t, a.RegisterService(reloadable))
+	require.NoError(t, a.RegisterModule(etcdModule))
+	require.NoError(t, a.Init(context.Background()))
+
+	zl, ok := logger.Get("reload-test").(*logger.ZapLogger)
+	require.True(t, ok)
+	assert.Equal(t, logger.LevelInfo, zl.Level())
+
+	// 配置未变化时不触发 Reloadable。
+	require.NoError(t, a.Reload(context.Background()))
+	assert.Empty(t, reloadable.changes)
+
+	write("debug", 4)
+	require.NoError(t, a.Reload(context.Background()))
+	assert.Equal(t, logger.LevelDebug, zl.Level())
+	require.Len(t, reloadable.changes, 1)
+	assert.Equal(t, "scheduler", reloadable.changes[0].Section)
+	assert.Equal(t, 4, sched.DefaultJobOptions().MaxRetries)
+
+	old := scheduler.DefaultConfig()
+	require.NoError(t, reloadable.changes[0].Old.Decode(old))
+	assert.Equal(t, 1, old.DefaultJobOptions.MaxRetries)
+	assert.Equal(t, []string{"a:2379"}, etcdModule.cfg.Endpoints)
+}
+
+func TestSchedulerService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	write := func(raw string) {
+		require.NoError(t, os.WriteFile(path, []byte(raw), 0o644))
+	}
+	write("scheduler:\n  jobs:\n    settle: {max_retries: 5}\n")
+
+	cfg := scheduler.DefaultConfig()
+	cfg.DefaultJobOptions.MaxRetries = 2
+	sched, err := scheduler.New(cfg)
+	require.NoError(t, err)
+	defer sched.Release()
+	noop := func() error { return nil }
+	settleID, err := sched.AddFunc("settle", "@every 1h", noop)
+	require.NoError(t, err)
+	otherID, err := sched.AddFunc("other", "@every 1h", noop)
+	require.NoError(t, err)
+
+	svc := NewSchedulerService(sched)
+	a := NewBaseApplication("test")
+	require.NoError(t, a.SetConfigPath(path))
+	require.NoError(t, a.RegisterService(svc))
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+	assert.True(t, sched.IsRunning())
+
+	// 配置段在创建时的配置基础上生效
+	job, _ := sched.GetJob(settleID)
+	assert.Equal(t, 5, job.Options.MaxRetries)
+	job, _ = sched.GetJob(otherID)
+	assert.Equal(t, 2, job.Options.MaxRetries)
+
+	write("scheduler:\n  default_job_options: {max_retries: 4}\n  jobs:\n    other: {max_retries: 8}\n")
+	require.NoError(t, a.Reload(ctx))
+	job, _ = sched.GetJob(settleID)
+	assert.Equal(t, 4, job.Options.MaxRetries)
+	job, _ = sched.GetJob(otherID)
+	assert.Equal(t, 8, job.Options.MaxRetries)
+
+	// 移除配置段后恢复创建时的配置
+	write("")
+	require.NoError(t, a.Reload(ctx))
+	job, _ = sched.GetJob(otherID)
+	assert.Equal(t, 2, job.Options.MaxRetries)
+	assert.Equal(t, 2, sched.DefaultJobOptions().MaxRetries)
+
+	require.NoError(t, a.Shutdown(ctx))
+	assert.False(t, sched.IsRunning())
+}
+
+func TestReloadLoggerLevels(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	write := func(overrides ...logger.LevelOverride) {
+		raw, err := yaml.Marshal(map[string]any{
+			"loggers": []map[string]any{{
+				"name":     "reload-levels.ws",
+				"filepath": filepath.Join(dir, "ws.log"),
+				"level":    "info",
+			}},
+			"logger_levels": overrides,
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, raw, 0o644))
+	}
+	write(logger.LevelOverride{Pattern: "reload-levels.*", Level: "error"})
+	t.Cleanup(logger.ResetLevelPatterns)
+
+	a := NewBaseApplication("test")
+	require.NoError(t, a.SetConfigPath(path))
+	require.NoError(t, a.Init(context.Background()))
+	level, err := logger.GetLevel("reload-levels.ws")
+	require.NoError(t, err)
+	assert.Equal(t, logger.LevelError, level)
+
+	write(logger.LevelOverride{Pattern: "reload-levels.*", Level: "debug"})
+	require.NoError(t, a.Reload(context.Background()))
+	level, err = logger.GetLevel("reload-levels.ws")
+	require.NoError(t, err)
+	assert.Equal(t, logger.LevelDebug, level)
+
+	// 移除覆盖后恢复具名日志自身配置的等级
+	write()
+	require.NoError(t, a.Reload(context.Background()))
+	level, err = logger.GetLevel("reload-levels.ws")
+	require.NoError(t, err)
+	assert.Equal(t, logger.LevelInfo, level)
+}
+
+func TestConfigDiff(t *testing.T) {
+	parse := func(raw string) Config {
+		var cfg Config
+		require.NoError(t, yaml.Unmarshal([]byte(raw), &cfg))
+		return cfg
+	}
+	old := parse("loggers: [{name: ws, level: info}]\netcd: {endpoints: [a]}\nclock: {reset_hour: 4}\n")
+	updated := parse("loggers: [{name: ws, level: debug}]\netcd:\n  endpoints:\n    - a\nscheduler: {timezone: UTC}\n")
+
+	assert.Equal(t, []string{"clock", "loggers", "scheduler"}, old.Diff(updated))
+	assert.Empty(t, old.Diff(old))
+
+	levels := parse("loggers: [{name: ws, level: info}]\nlogger_levels: [{pattern: 'w*', level: debug}]\n")
+	assert.Equal(t, []string{"clock", "etcd", "loggers"}, old.Diff(levels))
+	assert.Empty(t, levels.Sections)
+}
+
+func TestConfigWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("scheduler: {timezone: UTC}\n"), 0o644))
+
+	sched, err := scheduler.New(scheduler.DefaultConfig())
+	require.NoError(t, err)
+	a := NewBaseApplication("test", WithConfigWatch(10*time.Millisecond))
+	require.NoError(t, a.SetConfigPath(path))
+	require.NoError(t, a.RegisterService(NewSchedulerService(sched)))
+	require.NoError(t, a.Start(context.Background()))
+	defer func() { require.NoError(t, a.Stop(context.Background())) }()
+
+	require.NoError(t, os.WriteFile(path, []byte("scheduler: {default_job_options: {max_retries: 8}}\n"), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	assert.Eventually(t, func() bool {
+		return sched.DefaultJobOptions().MaxRetries == 8
+	}, time.Second, 10*time.Millisecond)
+}
+
+// drainingComponent 在 Drain 中记录事件，并可按需阻塞直到 ctx 结束。
+type drainingComponent struct {
+	fakeComponent
+	block bool
+	ready func() bool
+	state []bool
+}
+
+func (c *drainingComponent) Drain(ctx context.Context) error {
+	c.rec.add("drain:" + c.id)
+	if c.ready != nil {
+		c.state = append(c.state, c.ready())
+	}
+	if c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestShutdownDrain(t *testing.T) {
+	rec := &recorder{}
+	a := NewBaseApplication("test")
+	ready := func() bool { return a.Readiness(context.Background()).Healthy() }
+	gateway := &drainingComponent{
+		fakeComponent: fakeComponent{id: "gateway", requires: []string{"etcd", "scheduler"}, rec: rec},
+		ready:         ready,
+	}
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec}))
+	require.NoError(t, a.RegisterService(&drainingComponent{fakeComponent: fakeComponent{id: "scheduler", rec: rec}}))
+	require.NoError(t, a.RegisterService(gateway))
+
+	var states []string
+	a.AddStateListener(func(from, to State) {
+		states = append(states, from.String()+"->"+to.String())
+	})
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+	require.NoError(t, a.Shutdown(ctx))
+
+	events := rec.list()
+	assert.NotContains(t, events, "drain:etcd")
+	assertBefore(t, events, "drain:gateway", "drain:scheduler")
+	assertBefore(t, events, "drain:scheduler", "stop:gateway")
+	assert.Equal(t, []bool{false}, gateway.state)
+	assert.Equal(t, []string{
+		"running->draining",
+		"draining->stopping",
+		"stopping->stopped",
+	}, states[len(states)-3:])
+}
+
+func TestShutdownFlushesLoggers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	logPath := filepath.Join(dir, "async.log")
+	raw, err := yaml.Marshal(map[string]any{
+		"loggers": []map[string]any{{
+			"name":     "shutdown-flush",
+			"filepath": logPath,
+			"async":    map[string]any{"enabled": true, "flush_interval": "1h"},
+		}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, raw, 0o644))
+
+	a := NewBaseApplication("test")
+	require.NoError(t, a.SetConfigPath(path))
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+
+	logger.Get("shutdown-flush").Info("last words")
+	require.NoError(t, a.Shutdown(ctx))
+
+	content, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "last words")
+}
+
+func TestDrainTimeout(t *testing.T) {
+	rec := &recorder{}
+	a := NewBaseApplication("test", WithTimeouts(Timeouts{Drain: 50 * time.Millisecond}))
+	require.NoError(t, a.RegisterService(&drainingComponent{
+		fakeComponent: fakeComponent{id: "gateway", rec: rec},
+		block:         true,
+	}))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+
+	err := a.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	var lifecycleErr *LifecycleError
+	require.ErrorAs(t, err, &lifecycleErr)
+	require.Len(t, lifecycleErr.Failures, 1)
+	assert.Equal(t, PhaseDrain, lifecycleErr.Failures[0].Phase)
+	assert.Equal(t, []string{"init:gateway", "start:gateway", "drain:gateway", "stop:gateway"}, rec.list())
+	assert.Equal(t, StateStopped, a.State())
+}
+
+// stuckDrainComponent 在 Drain 中忽略 ctx，阻塞到 release 关闭后记录返回事件。
+type stuckDrainComponent struct {
+	fakeComponent
+	release chan struct{}
+}
+
+func (c *stuckDrainComponent) Drain(_ context.Context) error {
+	<-c.release
+	c.rec.add("drain-returned:" + c.id)
+	return nil
+}
+
+func TestStopAwaitsAbandonedDrain(t *testing.T) {
+	rec := &recorder{}
+	stuck := &stuckDrainComponent{
+		fakeComponent: fakeComponent{id: "gateway", rec: rec},
+		release:       make(chan struct{}),
+	}
+	a := NewBaseApplication("test", WithTimeouts(Timeouts{Drain: 20 * time.Millisecond, Stop: time.Second}))
+	require.NoError(t, a.RegisterService(stuck))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+	time.AfterFunc(100*time.Millisecond, func() { close(stuck.release) })
+
+	err := a.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"init:gateway", "start:gateway", "drain-returned:gateway", "stop:gateway"}, rec.list())
+}
+
+func TestStopAbandonedDrainTimeout(t *testing.T) {
+	rec := &recorder{}
+	stuck := &stuckDrainComponent{
+		fakeComponent: fakeComponent{id: "gateway", rec: rec},
+		release:       make(chan struct{}),
+	}
+	defer close(stuck.release)
+	a := NewBaseApplication("test", WithTimeouts(Timeouts{Drain: 20 * time.Millisecond, Stop: 20 * time.Millisecond}))
+	require.NoError(t, a.RegisterService(stuck))
+
+	ctx := context.Background()
+	require.NoError(t, a.Start(ctx))
+
+	err := a.Shutdown(ctx)
+	assert.ErrorIs(t, err, errPreviousCallRunning)
+	assert.Contains(t, err.Error(), "stop gateway")
+	assert.Equal(t, []string{"init:gateway", "start:gateway"}, rec.list())
+}
+
+func TestDependencyVersions(t *testing.T) {
+	rec := &recorder{}
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "storage", version: "v1.4.2", rec: rec}))
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec}))
+	require.NoError(t, a.RegisterService(&fakeComponent{
+		id:       "gateway",
+		version:  "2.0.0",
+		requires: []string{"storage>=1.2.0,<2.0.0", "etcd"},
+		rec:      rec,
+	}))
+
+	graph, err := a.Graph()
+	require.NoError(t, err)
+	require.Len(t, graph, 3)
+	assert.Equal(t, GraphNode{
+		ID:      "gateway",
+		Kind:    KindService,
+		Version: "2.0.0",
+		Requires: []GraphEdge{
+			{ID: "storage", Constraint: ">=1.2.0,<2.0.0", Version: "v1.4.2"},
+			{ID: "etcd", Version: "1.0.0"},
+		},
+	}, graph[2])
+	assert.Equal(t, "module storage@v1.4.2\n"+
+		"module etcd@1.0.0\n"+
+		"service gateway@2.0.0 -> storage@v1.4.2 (>=1.2.0,<2.0.0), etcd@1.0.0\n", graph.String())
+	require.NoError(t, a.Init(context.Background()))
+
+	drifted := NewBaseApplication("test")
+	require.NoError(t, drifted.RegisterModule(&fakeComponent{id: "storage", version: "1.1.9", rec: rec}))
+	require.NoError(t, drifted.RegisterService(&fakeComponent{id: "gateway", requires: []string{"storage^1.2"}, rec: rec}))
+	err = drifted.Init(context.Background())
+	assert.ErrorIs(t, err, errIncompatibleVersion)
+	assert.Contains(t, err.Error(), `"gateway" requires "storage^1.2", found 1.1.9`)
+	assert.Equal(t, StateFailed, drifted.State())
+
+	invalid := NewBaseApplication("test")
+	require.NoError(t, invalid.RegisterModule(&fakeComponent{id: "storage", rec: rec}))
+	require.NoError(t, invalid.RegisterService(&fakeComponent{id: "gateway", requires: []string{"storage>=one"}, rec: rec}))
+	assert.ErrorIs(t, invalid.Init(context.Background()), errInvalidRequirement)
+}
+
+func TestParseDependency(t *testing.T) {
+	tests := []struct {
+		raw     string
+		version string
+		allowed bool
+	}{
+		{"storage", "0.1.0", true},
+		{"storage>=1.2.0", "1.2.0", true},
+		{"storage >= 1.2", "1.1.9", false},
+		{"storage>1.2.0", "1.2.0", false},
+		{"storage<2", "2.0.0-rc.1", true},
+		{"storage<=1.2.0", "v1.2.0+build.7", true},
+		{"storage==1.2.0", "1.2.1", false},
+		{"storage!=1.2.0", "1.2.1", true},
+		{"storage~1.2.3", "1.2.9", true},
+		{"storage~1.2.3", "1.3.0", false},
+		{"storage^1.2.3", "1.9.0", true},
+		{"storage^1.2.3", "2.0.0", false},
+		{"storage^0.2.1", "0.3.0", false},
+		{"storage^1.2.0", "1.3.0-rc.1", false},
+		{"storage^1.2.0", "2.0.0-rc.1", false},
+		{"storage~1.2.0", "1.2.5-beta", false},
+		{"storage^1.2.0-rc.1", "1.2.0-rc.2", true},
+		{"storage^1.2.0-rc.1", "1.2.0", true},
+		{"storage^1.2.0-rc.1", "1.3.0", true},
+		{"storage^1.2.0-rc.1", "1.3.0-rc.1", false},
+		{"storage~1.2.0-rc.1", "1.2.0-rc.0", false},
+		{"storage>=1.0.0-alpha.2", "1.0.0-alpha.10", true},
+		{"storage>=1.0.0-alpha", "1.0.0-1", false},
+	}
+	for _, tt := range tests {
+		dep, err := ParseDependency(tt.raw)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, "storage", dep.ID, tt.raw)
+		ok, err := dep.Allows(tt.version)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.allowed, ok, "%s allows %s", tt.raw, tt.version)
+	}
+
+	for _, raw := range []string{">=1.0.0", "storage>=", "storage>=1.a", "storage>=1.2.3.4"} {
+		_, err := ParseDependency(raw)
+		assert.ErrorIs(t, err, errInvalidRequirement, raw)
+	}
+	_, err := Dependency{ID: "storage", Constraint: ">=1.0.0"}.Allows("latest")
+	assert.ErrorIs(t, err, errInvalidVersion)
+}
+
+func TestComponentState(t *testing.T) {
+	rec := &recorder{}
+	a := NewBaseApplication("test")
+	require.NoError(t, a.RegisterModule(&fakeComponent{id: "etcd", rec: rec}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "gateway", requires: []string{"etcd"}, rec: rec, startErr: errors.New("bind failed")}))
+	require.NoError(t, a.RegisterService(&fakeComponent{id: "chat", requires: []string{"gateway"}, rec: rec}))
+
+	ctx := context.Background()
+	require.NoError(t, a.Init(ctx))
+	assert.Equal(t, StateInitialized, a.ComponentState("etcd"))
+	assert.Equal(t, StateInitialized, a.ComponentState("chat"))
+
+	require.Error(t, a.Start(ctx))
+	assert.Equal(t, StateStopped, a.ComponentState("etcd"))
+	assert.Equal(t, StateFailed, a.ComponentState("gateway"))
+	assert.Equal(t, StateInitialized, a.ComponentState("chat"))
+	assert.Equal(t, StateCreated, a.ComponentState("unknown"))
+}
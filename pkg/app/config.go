@@ -1,27 +1,84 @@
 package app
 
 import (
-	"os"
+	"errors"
+	"reflect"
 
 	"github.com/lk2023060901/zeus-go/pkg/logger"
 	"gopkg.in/yaml.v3"
 )
 
+var errNilConfigTarget = errors.New("app: config decode target is nil")
+
 // Config 表示应用配置结构。
 type Config struct {
 	// Loggers 表示日志配置段。
 	Loggers []logger.NamedConfig `yaml:"loggers"`
+	// LoggerLevels 表示按名称模式覆盖的日志等级，在 Diff 结果中与日志配置段一同记为 "loggers"。
+	LoggerLevels []logger.LevelOverride `yaml:"logger_levels"`
+
+	// Sections 表示其余具名配置段，键为配置段名称，由各组件自行解码。
+	Sections map[string]yaml.Node `yaml:",inline"`
+}
+
+// ConfigDecoder 将配置段解码到组件自定义的结构体。
+type ConfigDecoder interface {
+	// Decode 将配置段解码到 out，配置段不存在时保持 out 不变。
+	Decode(out any) error
+}
+
+// Configurable 定义可从应用配置中读取自身配置段的模块或服务。
+// 应用在 Init 阶段、初始化任何组件之前，将与组件 ID 同名的配置段交给 Configure。
+type Configurable interface {
+	Configure(dec ConfigDecoder) error
 }
 
-// LoadConfigFromFile 从 YAML 文件加载应用配置。
+// LoadConfigFromFile 从 YAML 文件加载应用配置，文件中的 ${VAR} 会被替换为环境变量的值。
 func LoadConfigFromFile(path string) (Config, error) {
-	raw, err := os.ReadFile(path)
-	if err != nil {
-		return Config{}, err
+	return LoadConfig(ConfigSource{Path: path})
+}
+
+// Has 判断是否存在指定名称的配置段。
+func (c Config) Has(section string) bool {
+	_, ok := c.Sections[section]
+	return ok
+}
+
+// Decode 将指定名称的配置段解码到 out，配置段不存在时保持 out 不变。
+func (c Config) Decode(section string, out any) error {
+	return c.Section(section).Decode(out)
+}
+
+// Section 返回指定名称配置段的解码器。
+func (c Config) Section(section string) ConfigDecoder {
+	node, ok := c.Sections[section]
+	if !ok {
+		return sectionDecoder{}
+	}
+	return sectionDecoder{node: &node}
+}
+
+type sectionDecoder struct {
+	node *yaml.Node
+}
+
+func (d sectionDecoder) Decode(out any) error {
+	if out == nil {
+		return errNilConfigTarget
+	}
+	if d.node == nil {
+		return nil
 	}
-	var cfg Config
-	if err := yaml.Unmarshal(raw, &cfg); err != nil {
-		return Config{}, err
+	return coerceLists(d.node, reflect.TypeOf(out)).Decode(out)
+}
+
+// configure 依次将同名配置段交给实现 Configurable 的组件，返回值与 components 一一对应。
+func configure(components []component, cfg Config) []error {
+	errs := make([]error, len(components))
+	for i, c := range components {
+		if cc, ok := c.(Configurable); ok {
+			errs[i] = cc.Configure(cfg.Section(c.ID()))
+		}
 	}
-	return cfg, nil
+	return errs
 }
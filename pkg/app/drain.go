@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+
+	"github.com/lk2023060901/zeus-go/pkg/service"
+)
+
+// Drain 让实现 service.Drainer 的模块、服务停止接收新的工作，并在排空期限内完成
+// 进行中的工作。组件在其全部依赖者排空后才排空；排空期间应用处于 StateDraining，
+// 就绪检查随之失败，服务监管与配置监听也会停止。Drain 不会停止任何组件，
+// 通常由 Shutdown 在 Stop 之前调用。
+func (a *BaseApplication) Drain(ctx context.Context) error {
+	a.mu.Lock()
+	if a.state == StateDraining || a.state == StateStopping {
+		a.mu.Unlock()
+		return errTransitioning
+	}
+	if !a.started || a.state != StateRunning {
+		a.mu.Unlock()
+		return nil
+	}
+	from := a.swapState(StateDraining)
+	order := append([]component(nil), a.order...)
+	a.mu.Unlock()
+	a.notifyState(from, StateDraining)
+	a.unwatchConfig()
+	a.unsupervise()
+
+	var lifecycleErr LifecycleError
+	a.record(ctx, &lifecycleErr, PhaseDrain, order, a.backward(ctx, PhaseDrain, order, drain))
+	return lifecycleErr.err()
+}
+
+// drain 排空单个组件，未实现 service.Drainer 的组件直接返回。
+func drain(c component, ctx context.Context) error {
+	if d, ok := c.(service.Drainer); ok {
+		return d.Drain(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/module"
+	"github.com/lk2023060901/zeus-go/pkg/service"
+)
+
+var (
+	errEmptyComponentID   = errors.New("app: component id is empty")
+	errDuplicateComponent = errors.New("app: duplicate component id")
+	errMissingDependency  = errors.New("app: missing dependency")
+	errDependencyCycle    = errors.New("app: dependency cycle detected")
+	errDependencyFailed   = errors.New("app: dependency failed")
+)
+
+const (
+	// KindModule 表示依赖图中的模块节点。
+	KindModule = "module"
+	// KindService 表示依赖图中的服务节点。
+	KindService = "service"
+)
+
+// Graph 表示按依赖顺序排列的模块、服务依赖图，被依赖者排在依赖者之前。
+type Graph []GraphNode
+
+// GraphNode 描述依赖图中的一个模块或服务。
+type GraphNode struct {
+	// ID 表示组件 ID。
+	ID string `json:"id"`
+	// Kind 表示组件类型，取值为 KindModule 或 KindService。
+	Kind string `json:"kind"`
+	// Version 表示组件版本，未声明版本的服务为空。
+	Version string `json:"version,omitempty"`
+	// Requires 表示组件声明的依赖。
+	Requires []GraphEdge `json:"requires,omitempty"`
+}
+
+// GraphEdge 描述一条已解析的依赖。
+type GraphEdge struct {
+	// ID 表示被依赖组件的 ID。
+	ID string `json:"id"`
+	// Constraint 表示声明的版本约束，为空表示不限制版本。
+	Constraint string `json:"constraint,omitempty"`
+	// Version 表示被依赖组件实际解析到的版本。
+	Version string `json:"version,omitempty"`
+}
+
+// Graph 返回按依赖顺序解析后的模块、服务依赖图，依赖缺失、成环或版本不满足约束时返回错误。
+func (a *BaseApplication) Graph() (Graph, error) {
+	a.mu.RLock()
+	modules := append([]module.Module(nil), a.modules...)
+	services := append([]service.Service(nil), a.services...)
+	a.mu.RUnlock()
+
+	order, err := sortComponents(collectComponents(modules, services))
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]string, len(order))
+	for _, m := range modules {
+		kinds[m.ID()] = KindModule
+	}
+	for _, s := range services {
+		kinds[s.ID()] = KindService
+	}
+	versions := make(map[string]string, len(order))
+	for _, c := range order {
+		versions[c.ID()] = versionOf(c)
+	}
+
+	graph := make(Graph, 0, len(order))
+	for _, c := range order {
+		node := GraphNode{ID: c.ID(), Kind: kinds[c.ID()], Version: versions[c.ID()]}
+		for _, raw := range c.Requires() {
+			dep, _ := ParseDependency(raw)
+			node.Requires = append(node.Requires, GraphEdge{
+				ID:         dep.ID,
+				Constraint: dep.Constraint,
+				Version:    versions[dep.ID],
+			})
+		}
+		graph = append(graph, node)
+	}
+	return graph, nil
+}
+
+// String 以每行一个组件的文本格式输出依赖图，例如：
+//
+//	module etcd@1.2.0
+//	service gateway@1.0.0 -> etcd@1.2.0 (>=1.2.0)
+func (g Graph) String() string {
+	var b strings.Builder
+	for _, node := range g {
+		b.WriteString(node.Kind + " " + versioned(node.ID, node.Version))
+		for i, edge := range node.Requires {
+			if i == 0 {
+				b.WriteString(" -> ")
+			} else {
+				b.WriteString(", ")
+			}
+			b.WriteString(versioned(edge.ID, edge.Version))
+			if edge.Constraint != "" {
+				b.WriteString(" (" + edge.Constraint + ")")
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func versioned(id, version string) string {
+	if version == "" {
+		return id
+	}
+	return id + "@" + version
+}
+
+// component 抽象模块与服务共有的生命周期，便于统一编排。
+type component interface {
+	ID() string
+	Requires() []string
+	Init(ctx context.Context) error
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// collectComponents 按注册顺序合并模块与服务，模块在前。
+func collectComponents(modules []module.Module, services []service.Service) []component {
+	components := make([]component, 0, len(modules)+len(services))
+	for _, m := range modules {
+		components = append(components, m)
+	}
+	for _, s := range services {
+		components = append(components, s)
+	}
+	return components
+}
+
+// sortComponents 按 Requires 声明的依赖关系对组件做拓扑排序，并校验依赖的版本约束；
+// 被依赖者排在依赖者之前，无依赖关系的组件保持注册顺序。
+func sortComponents(components []component) ([]component, error) {
+	index := make(map[string]int, len(components))
+	for i, c := range components {
+		id := c.ID()
+		if id == "" {
+			return nil, errEmptyComponentID
+		}
+		if _, exists := index[id]; exists {
+			return nil, fmt.Errorf("%w: %q", errDuplicateComponent, id)
+		}
+		index[id] = i
+	}
+	for _, c := range components {
+		for _, raw := range c.Requires() {
+			dep, err := ParseDependency(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", c.ID(), err)
+			}
+			target, exists := index[dep.ID]
+			if !exists {
+				return nil, fmt.Errorf("%w: %q requires %q", errMissingDependency, c.ID(), dep.ID)
+			}
+			if err := checkVersion(c, dep, components[target]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(components))
+	order := make([]component, 0, len(components))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		id := components[i].ID()
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for j, p := range path {
+				if p == id {
+					start = j
+					break
+				}
+			}
+			cycle := append(append([]string(nil), path[start:]...), id)
+			return fmt.Errorf("%w: %s", errDependencyCycle, strings.Join(cycle, " -> "))
+		}
+
+		states[i] = visiting
+		path = append(path, id)
+		for _, dep := range requiresOf(components[i]) {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		states[i] = visited
+		order = append(order, components[i])
+		return nil
+	}
+
+	for i := range components {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// dependents 返回组件集合内每个组件的直接依赖者 ID 列表。
+func dependents(components []component) map[string][]string {
+	present := make(map[string]struct{}, len(components))
+	for _, c := range components {
+		present[c.ID()] = struct{}{}
+	}
+	result := make(map[string][]string, len(components))
+	for _, c := range components {
+		for _, dep := range requiresOf(c) {
+			if _, ok := present[dep]; ok {
+				result[dep] = append(result[dep], c.ID())
+			}
+		}
+	}
+	return result
+}
+
+// schedule 按依赖关系并发执行 fn：组件在 waitFor 返回的前置组件全部完成后立即执行，
+// 前置组件必须在 components 中排在其之前。strict 为 true 时，任一前置组件失败
+// 都会使当前组件跳过执行并返回 errDependencyFailed。
+// 返回值与 components 一一对应，nil 表示执行成功。
+func schedule(components []component, waitFor func(c component) []string, strict bool, fn func(c component) error) []error {
+	futures := make(map[string]*conc.Future[struct{}], len(components))
+	ordered := make([]*conc.Future[struct{}], 0, len(components))
+	for _, c := range components {
+		var prev []*conc.Future[struct{}]
+		for _, id := range waitFor(c) {
+			if f, ok := futures[id]; ok {
+				prev = append(prev, f)
+			}
+		}
+		f := conc.Go(func() (struct{}, error) {
+			if err := conc.BlockOnAll(prev...); err != nil && strict {
+				return struct{}{}, errDependencyFailed
+			}
+			return struct{}{}, fn(c)
+		})
+		futures[c.ID()] = f
+		ordered = append(ordered, f)
+	}
+
+	errs := make([]error, len(ordered))
+	for i, f := range ordered {
+		errs[i] = f.Err()
+	}
+	return errs
+}
+
+// scheduleReverse 按依赖的逆序并发执行 fn：组件在其全部依赖者完成后才执行，
+// 依赖者执行失败不会阻止其依赖的组件继续执行。返回值与 components 一一对应。
+func scheduleReverse(components []component, fn func(c component) error) []error {
+	reversed := make([]component, 0, len(components))
+	for i := len(components) - 1; i >= 0; i-- {
+		reversed = append(reversed, components[i])
+	}
+	deps := dependents(components)
+	reversedErrs := schedule(reversed, func(c component) []string {
+		return deps[c.ID()]
+	}, false, fn)
+
+	errs := make([]error, len(components))
+	for i, err := range reversedErrs {
+		errs[len(components)-1-i] = err
+	}
+	return errs
+}
+
+// requiresOf 返回组件依赖的组件 ID 列表，忽略版本约束。
+func requiresOf(c component) []string {
+	raw := c.Requires()
+	ids := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if dep, err := ParseDependency(r); err == nil {
+			ids = append(ids, dep.ID)
+		} else {
+			ids = append(ids, r)
+		}
+	}
+	return ids
+}
+
+// versionOf 返回组件的版本号，服务可选实现 Version 方法。
+func versionOf(c component) string {
+	if v, ok := c.(interface{ Version() string }); ok {
+		return v.Version()
+	}
+	return ""
+}
+
+// checkVersion 校验 target 的版本是否满足 c 对其声明的版本约束。
+func checkVersion(c component, dep Dependency, target component) error {
+	if dep.Constraint == "" {
+		return nil
+	}
+	version := versionOf(target)
+	if version == "" {
+		return fmt.Errorf("%w: %q requires %q, but %q has no version", errIncompatibleVersion, c.ID(), dep.String(), dep.ID)
+	}
+	ok, err := dep.Allows(version)
+	if err != nil {
+		return fmt.Errorf("%w: %q requires %q: %v", errIncompatibleVersion, c.ID(), dep.String(), err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q requires %q, found %s", errIncompatibleVersion, c.ID(), dep.String(), version)
+	}
+	return nil
+}
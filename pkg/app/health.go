@@ -0,0 +1,231 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+)
+
+var errNilHealthChecker = errors.New("app: health checker is nil")
+
+// defaultHealthCheckTimeout 表示单个健康检查项的默认超时时间。
+const defaultHealthCheckTimeout = 3 * time.Second
+
+// HealthChecker 定义可选的就绪检查接口，模块、服务或 etcd.Client 等依赖实现后参与就绪报告。
+type HealthChecker interface {
+	// HealthCheck 检查自身是否可对外提供服务，返回 nil 表示健康。
+	HealthCheck(ctx context.Context) error
+}
+
+// LivenessChecker 定义可选的存活检查接口，实现后参与存活报告。
+type LivenessChecker interface {
+	// LivenessCheck 检查自身是否仍在正常运转，返回非 nil 表示需要重启进程。
+	LivenessCheck(ctx context.Context) error
+}
+
+// HealthStatus 表示健康状态。
+type HealthStatus string
+
+const (
+	// HealthUp 表示健康。
+	HealthUp HealthStatus = "up"
+	// HealthDown 表示不健康。
+	HealthDown HealthStatus = "down"
+)
+
+// CheckResult 表示单项检查结果。
+type CheckResult struct {
+	// Name 表示检查项名称，组件检查项使用组件 ID。
+	Name string `json:"name"`
+	// Status 表示检查结果状态。
+	Status HealthStatus `json:"status"`
+	// Error 表示失败原因。
+	Error string `json:"error,omitempty"`
+	// Duration 表示检查耗时。
+	Duration time.Duration `json:"duration"`
+}
+
+// HealthReport 表示聚合后的健康报告。
+type HealthReport struct {
+	// Status 表示整体状态，任一检查项失败即为 down。
+	Status HealthStatus `json:"status"`
+	// State 表示应用当前的生命周期状态。
+	State string `json:"state"`
+	// Checks 表示各检查项结果。
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// Healthy 判断报告是否为健康状态。
+func (r HealthReport) Healthy() bool {
+	return r.Status == HealthUp
+}
+
+type namedCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// AddHealthCheck 注册一个额外的就绪检查项，例如 etcd.Client。
+func (a *BaseApplication) AddHealthCheck(name string, c HealthChecker) error {
+	if c == nil {
+		return errNilHealthChecker
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.healthChecks = append(a.healthChecks, namedCheck{name: name, check: c.HealthCheck})
+	return nil
+}
+
+// Liveness 返回存活报告：应用未处于失败状态且所有存活检查通过即为健康。
+func (a *BaseApplication) Liveness(ctx context.Context) HealthReport {
+	var checks []namedCheck
+	for _, c := range a.components() {
+		if lc, ok := c.(LivenessChecker); ok {
+			checks = append(checks, namedCheck{name: c.ID(), check: lc.LivenessCheck})
+		}
+	}
+	state := a.State()
+	return buildReport(ctx, a.opts.healthCheckTimeout, state, state != StateFailed, checks)
+}
+
+// Readiness 返回就绪报告：应用处于运行状态且所有就绪检查通过即为健康。
+func (a *BaseApplication) Readiness(ctx context.Context) HealthReport {
+	var checks []namedCheck
+	for _, c := range a.components() {
+		if hc, ok := c.(HealthChecker); ok {
+			checks = append(checks, namedCheck{name: c.ID(), check: hc.HealthCheck})
+		}
+	}
+	a.mu.RLock()
+	checks = append(checks, a.healthChecks...)
+	a.mu.RUnlock()
+
+	state := a.State()
+	return buildReport(ctx, a.opts.healthCheckTimeout, state, state == StateRunning, checks)
+}
+
+// HealthHandler 返回提供 /healthz 与 /readyz 的 HTTP 处理器，不健康时返回 503。
+func (a *BaseApplication) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, a.Liveness(r.Context()))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, a.Readiness(r.Context()))
+	})
+	return mux
+}
+
+// WithHealthServer 在 addr 上启用内置健康检查 HTTP 服务，随 Start 启动、随 Stop 关闭。
+func WithHealthServer(addr string) Option {
+	return func(o *options) {
+		o.healthAddr = addr
+	}
+}
+
+// WithHealthCheckTimeout 设置单个健康检查项的超时时间，默认 3 秒，0 表示不限制。
+// 超时的检查项记为失败，避免单个检查阻塞 /healthz 与 /readyz。
+func WithHealthCheckTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.healthCheckTimeout = d
+	}
+}
+
+// components 返回已注册的全部组件，注册阶段亦可调用。
+func (a *BaseApplication) components() []component {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return collectComponents(a.modules, a.services)
+}
+
+func (a *BaseApplication) startHealthServer() error {
+	if a.opts.healthAddr == "" {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.healthServer != nil {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", a.opts.healthAddr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           a.HealthHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	a.healthServer = srv
+	conc.Go(func() (struct{}, error) {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return struct{}{}, err
+		}
+		return struct{}{}, nil
+	})
+	return nil
+}
+
+func (a *BaseApplication) stopHealthServer(ctx context.Context) error {
+	a.mu.Lock()
+	srv := a.healthServer
+	a.healthServer = nil
+	a.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+// buildReport 并发执行所有检查项并汇总结果，单个检查项超过 timeout 未返回时记为失败。
+func buildReport(ctx context.Context, timeout time.Duration, state State, stateOK bool, checks []namedCheck) HealthReport {
+	futures := make([]*conc.Future[CheckResult], 0, len(checks))
+	for _, c := range checks {
+		futures = append(futures, conc.Go(func() (CheckResult, error) {
+			begin := time.Now()
+			err := invoke(ctx, timeout, c.check)
+			result := CheckResult{
+				Name:     c.name,
+				Status:   HealthUp,
+				Duration: time.Since(begin),
+			}
+			if err != nil {
+				result.Status = HealthDown
+				result.Error = err.Error()
+			}
+			return result, nil
+		}))
+	}
+
+	report := HealthReport{
+		Status: HealthUp,
+		State:  state.String(),
+	}
+	if !stateOK {
+		report.Status = HealthDown
+	}
+	for _, f := range futures {
+		result := f.Value()
+		if result.Status == HealthDown {
+			report.Status = HealthDown
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+func writeReport(w http.ResponseWriter, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if report.Healthy() {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
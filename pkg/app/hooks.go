@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// State 表示应用所处的生命周期状态。
+type State int32
+
+const (
+	// StateCreated 表示应用已创建，尚未初始化。
+	StateCreated State = iota
+	// StateInitializing 表示应用正在初始化。
+	StateInitializing
+	// StateInitialized 表示应用已完成初始化。
+	StateInitialized
+	// StateStarting 表示应用正在启动。
+	StateStarting
+	// StateRunning 表示应用已启动并在运行。
+	StateRunning
+	// StateDraining 表示应用正在排空进行中的工作，此时就绪检查失败。
+	StateDraining
+	// StateStopping 表示应用正在停止。
+	StateStopping
+	// StateStopped 表示应用已停止。
+	StateStopped
+	// StateFailed 表示应用在某个生命周期阶段失败。
+	StateFailed
+)
+
+// String 返回状态名称。
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateInitializing:
+		return "initializing"
+	case StateInitialized:
+		return "initialized"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("state(%d)", int32(s))
+	}
+}
+
+// StateListener 在应用状态变化时被调用。
+type StateListener func(from, to State)
+
+// Hooks 定义应用生命周期钩子，未设置的字段会被忽略。
+type Hooks struct {
+	// BeforeInit 在初始化任何组件之前执行，返回错误会中止初始化。
+	BeforeInit func(ctx context.Context) error
+	// AfterStart 在全部组件启动成功后执行，返回错误会回滚停止所有组件。
+	AfterStart func(ctx context.Context) error
+	// BeforeStop 在停止任何组件之前执行，返回错误不会中断停止流程。
+	BeforeStop func(ctx context.Context) error
+	// AfterStop 在全部组件停止之后执行。
+	AfterStop func(ctx context.Context) error
+	// OnComponentFailed 在组件的任一生命周期阶段失败时执行。
+	OnComponentFailed func(ctx context.Context, err *ComponentError)
+}
+
+// AddHooks 注册一组生命周期钩子，多组钩子按注册顺序执行。
+func (a *BaseApplication) AddHooks(h Hooks) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, h)
+}
+
+// AddStateListener 注册状态监听器，监听器在状态变化后同步调用。
+func (a *BaseApplication) AddStateListener(l StateListener) {
+	if l == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.listeners = append(a.listeners, l)
+}
+
+// State 返回应用当前的生命周期状态。
+func (a *BaseApplication) State() State {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.state
+}
+
+// ComponentState 返回指定组件的生命周期状态，未注册的组件返回 StateCreated。
+// 组件状态与应用状态共用 State：组件失败后为 StateFailed，重启成功后恢复为 StateRunning。
+func (a *BaseApplication) ComponentState(id string) State {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.componentStates[id]
+}
+
+// setComponentState 更新单个组件的状态。
+func (a *BaseApplication) setComponentState(id string, to State) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.componentStates == nil {
+		a.componentStates = make(map[string]State)
+	}
+	a.componentStates[id] = to
+}
+
+// phaseState 返回组件完成 phase 后应处于的状态；因依赖失败被跳过的组件、
+// 配置阶段与热更新阶段的成功不改变组件状态，热更新失败也不会使组件进入失败状态。
+func phaseState(phase Phase, err error) (State, bool) {
+	if errors.Is(err, errDependencyFailed) || phase == PhaseReload {
+		return 0, false
+	}
+	if err != nil {
+		return StateFailed, true
+	}
+	switch phase {
+	case PhaseInit:
+		return StateInitialized, true
+	case PhaseStart:
+		return StateRunning, true
+	case PhaseDrain:
+		return StateDraining, true
+	case PhaseStop, PhaseRollback:
+		return StateStopped, true
+	default:
+		return 0, false
+	}
+}
+
+// setState 切换应用状态并通知监听器。
+func (a *BaseApplication) setState(to State) {
+	a.mu.Lock()
+	from := a.swapState(to)
+	a.mu.Unlock()
+	a.notifyState(from, to)
+}
+
+// swapState 切换应用状态并返回原状态，调用方需持有 a.mu。
+func (a *BaseApplication) swapState(to State) State {
+	from := a.state
+	a.state = to
+	return from
+}
+
+// notifyState 同步通知所有状态监听器，调用方不得持有 a.mu。
+func (a *BaseApplication) notifyState(from, to State) {
+	if from == to {
+		return
+	}
+	a.mu.RLock()
+	listeners := append([]StateListener(nil), a.listeners...)
+	a.mu.RUnlock()
+	for _, l := range listeners {
+		l(from, to)
+	}
+}
+
+// runHooks 依次执行 pick 选出的钩子，返回所有钩子错误的合并结果。
+func (a *BaseApplication) runHooks(ctx context.Context, name string, pick func(Hooks) func(context.Context) error) error {
+	a.mu.RLock()
+	hooks := append([]Hooks(nil), a.hooks...)
+	a.mu.RUnlock()
+
+	var errs []error
+	for _, h := range hooks {
+		fn := pick(h)
+		if fn == nil {
+			continue
+		}
+		if err := fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("app: %s hook: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// record 记录组件失败、更新组件状态并触发 OnComponentFailed 钩子。
+func (a *BaseApplication) record(ctx context.Context, lifecycleErr *LifecycleError, phase Phase, components []component, errs []error) {
+	for i, c := range components {
+		if to, ok := phaseState(phase, errs[i]); ok {
+			a.setComponentState(c.ID(), to)
+		}
+	}
+	failures := lifecycleErr.record(phase, components, errs)
+	if len(failures) == 0 {
+		return
+	}
+
+	for _, f := range failures {
+		a.componentFailed(ctx, f)
+	}
+}
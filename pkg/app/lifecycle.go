@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+)
+
+var errPreviousCallRunning = errors.New("app: previous lifecycle call still running")
+
+// Phase 表示组件的生命周期阶段。
+type Phase string
+
+const (
+	// PhaseConfigure 表示将配置段交给组件的配置阶段。
+	PhaseConfigure Phase = "configure"
+	// PhaseInit 表示初始化阶段。
+	PhaseInit Phase = "init"
+	// PhaseStart 表示启动阶段。
+	PhaseStart Phase = "start"
+	// PhaseDrain 表示停止前排空进行中工作的阶段。
+	PhaseDrain Phase = "drain"
+	// PhaseStop 表示停止阶段。
+	PhaseStop Phase = "stop"
+	// PhaseRollback 表示启动失败后停止已启动组件的回滚阶段。
+	PhaseRollback Phase = "rollback"
+)
+
+// Timeouts 定义各生命周期阶段的超时时间，0 表示不限制。
+type Timeouts struct {
+	// Init 表示初始化阶段的超时时间。
+	Init time.Duration
+	// Start 表示启动阶段的超时时间。
+	Start time.Duration
+	// Stop 表示停止阶段（含回滚）的超时时间。
+	Stop time.Duration
+	// Drain 表示排空阶段的超时时间，即等待进行中工作完成的期限。
+	Drain time.Duration
+}
+
+// of 返回指定阶段的超时时间，回滚阶段沿用 Stop。
+func (t Timeouts) of(phase Phase) time.Duration {
+	switch phase {
+	case PhaseInit:
+		return t.Init
+	case PhaseStart:
+		return t.Start
+	case PhaseStop, PhaseRollback:
+		return t.Stop
+	case PhaseDrain:
+		return t.Drain
+	default:
+		return 0
+	}
+}
+
+// ComponentError 描述单个组件在某个生命周期阶段的失败。
+type ComponentError struct {
+	// ID 表示失败组件的 ID。
+	ID string
+	// Phase 表示失败发生的阶段。
+	Phase Phase
+	// Err 表示失败原因。
+	Err error
+}
+
+// Error 实现 error 接口。
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Phase, e.ID, e.Err)
+}
+
+// Unwrap 返回底层错误，便于 errors.Is/As 判断。
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// LifecycleError 汇总一次生命周期操作中所有失败的组件。
+type LifecycleError struct {
+	// Failures 按阶段与依赖顺序列出失败的组件。
+	Failures []*ComponentError
+}
+
+// Error 实现 error 接口。
+func (e *LifecycleError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		parts = append(parts, f.Error())
+	}
+	return "app: " + strings.Join(parts, "; ")
+}
+
+// Unwrap 返回所有组件错误，便于 errors.Is/As 判断。
+func (e *LifecycleError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f)
+	}
+	return errs
+}
+
+// record 记录 components 在 phase 中的失败并返回新增的失败，errs 与 components
+// 一一对应；因依赖失败而跳过的组件不计入。
+func (e *LifecycleError) record(phase Phase, components []component, errs []error) []*ComponentError {
+	n := len(e.Failures)
+	for i, err := range errs {
+		if err == nil || errors.Is(err, errDependencyFailed) {
+			continue
+		}
+		e.Failures = append(e.Failures, &ComponentError{
+			ID:    components[i].ID(),
+			Phase: phase,
+			Err:   err,
+		})
+	}
+	return e.Failures[n:]
+}
+
+// err 在存在失败时返回自身，否则返回 nil。
+func (e *LifecycleError) err() error {
+	if len(e.Failures) == 0 {
+		return nil
+	}
+	return e
+}
+
+// joinErrors 合并非空错误，仅有一个非空错误时原样返回。
+func joinErrors(errs ...error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+	switch len(nonNil) {
+	case 0:
+		return nil
+	case 1:
+		return nonNil[0]
+	default:
+		return errors.Join(nonNil...)
+	}
+}
+
+// invoke 在超时约束下执行 fn。fn 未响应 ctx 取消时直接返回超时错误，
+// 避免单个组件阻塞整个生命周期流程。
+func invoke(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	future := conc.Go(func() (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	select {
+	case <-future.Inner():
+		return future.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// invokeComponent 与 invoke 相同，但会记录超时后仍在执行的调用：对同一组件执行下一次
+// 调用前先等待其返回，等待时间计入本次超时，避免例如 Stop 与仍在执行的 Drain 并发。
+func (a *BaseApplication) invokeComponent(ctx context.Context, phase Phase, c component, fn func(c component, ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	id := c.ID()
+	if timeout := a.componentTimeout(id, phase); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := a.awaitAbandoned(ctx, id); err != nil {
+		return err
+	}
+
+	future := conc.Go(func() (struct{}, error) {
+		return struct{}{}, fn(c, ctx)
+	})
+	select {
+	case <-future.Inner():
+		return future.Err()
+	case <-ctx.Done():
+		a.abandon(id, future)
+		return ctx.Err()
+	}
+}
+
+// abandon 记录组件超时后仍在执行的调用。
+func (a *BaseApplication) abandon(id string, future *conc.Future[struct{}]) {
+	a.abandonedMu.Lock()
+	defer a.abandonedMu.Unlock()
+	if a.abandoned == nil {
+		a.abandoned = make(map[string]*conc.Future[struct{}])
+	}
+	a.abandoned[id] = future
+}
+
+// awaitAbandoned 等待组件上一次超时后仍在执行的调用返回，ctx 先结束时返回错误。
+func (a *BaseApplication) awaitAbandoned(ctx context.Context, id string) error {
+	a.abandonedMu.Lock()
+	future := a.abandoned[id]
+	a.abandonedMu.Unlock()
+	if future == nil {
+		return nil
+	}
+
+	select {
+	case <-future.Inner():
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", errPreviousCallRunning, ctx.Err())
+	}
+	a.abandonedMu.Lock()
+	if a.abandoned[id] == future {
+		delete(a.abandoned, id)
+	}
+	a.abandonedMu.Unlock()
+	return nil
+}
+
+// forward 在 phase 的超时约束下按依赖顺序并发执行 fn，返回值与 order 一一对应。
+func (a *BaseApplication) forward(ctx context.Context, phase Phase, order []component, fn func(c component, ctx context.Context) error) []error {
+	ctx, cancel := a.phaseContext(ctx, phase)
+	defer cancel()
+	return schedule(order, requiresOf, true, func(c component) error {
+		return a.invokeComponent(ctx, phase, c, fn)
+	})
+}
+
+// backward 在 phase 的超时约束下按依赖的逆序并发执行 fn，返回值与 components 一一对应。
+func (a *BaseApplication) backward(ctx context.Context, phase Phase, components []component, fn func(c component, ctx context.Context) error) []error {
+	ctx, cancel := a.phaseContext(ctx, phase)
+	defer cancel()
+	return scheduleReverse(components, func(c component) error {
+		return a.invokeComponent(ctx, phase, c, fn)
+	})
+}
+
+func (a *BaseApplication) phaseContext(ctx context.Context, phase Phase) (context.Context, context.CancelFunc) {
+	if timeout := a.opts.timeouts.of(phase); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (a *BaseApplication) componentTimeout(id string, phase Phase) time.Duration {
+	if t, ok := a.opts.componentTimeouts[id]; ok {
+		if timeout := t.of(phase); timeout > 0 {
+			return timeout
+		}
+	}
+	return a.opts.defaultComponentTimeouts.of(phase)
+}
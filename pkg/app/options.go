@@ -0,0 +1,54 @@
+package app
+
+import "time"
+
+// Option 应用选项
+type Option func(*options)
+
+type options struct {
+	// timeouts 表示整个阶段的全局超时
+	timeouts Timeouts
+	// defaultComponentTimeouts 表示单个组件的默认超时
+	defaultComponentTimeouts Timeouts
+	// componentTimeouts 表示按组件 ID 覆盖的超时
+	componentTimeouts map[string]Timeouts
+	// supervisor 表示服务监管配置，nil 表示不启用
+	supervisor *SupervisorConfig
+	// healthAddr 表示内置健康检查服务监听地址，空表示不启用
+	healthAddr string
+	// healthCheckTimeout 表示单个健康检查项的超时，0 表示不限制
+	healthCheckTimeout time.Duration
+	// envPrefix 表示覆盖配置的环境变量前缀，空表示不读取环境变量
+	envPrefix string
+	// watchInterval 表示配置文件轮询间隔，0 表示不监听文件变化
+	watchInterval time.Duration
+}
+
+func defaultOptions() options {
+	return options{
+		componentTimeouts:  make(map[string]Timeouts),
+		healthCheckTimeout: defaultHealthCheckTimeout,
+	}
+}
+
+// WithTimeouts 设置 Init、Start、Stop 各阶段整体的超时时间。
+func WithTimeouts(t Timeouts) Option {
+	return func(o *options) {
+		o.timeouts = t
+	}
+}
+
+// WithComponentTimeouts 设置单个组件在各阶段的默认超时时间。
+func WithComponentTimeouts(t Timeouts) Option {
+	return func(o *options) {
+		o.defaultComponentTimeouts = t
+	}
+}
+
+// WithComponentTimeout 为指定 ID 的组件单独设置各阶段超时时间，
+// 未设置（为 0）的阶段沿用 WithComponentTimeouts 的默认值。
+func WithComponentTimeout(id string, t Timeouts) Option {
+	return func(o *options) {
+		o.componentTimeouts[id] = t
+	}
+}
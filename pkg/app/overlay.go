@@ -0,0 +1,385 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var errInvalidOverride = errors.New("app: invalid config override")
+
+// interpolatePattern 匹配 ${VAR} 与 ${VAR:-default} 形式的变量引用。
+var interpolatePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ConfigSource 描述分层配置的来源，后加载的来源覆盖先加载的来源：
+// YAML 文件 → 环境变量 → 命令行覆盖项。
+type ConfigSource struct {
+	// Path 表示 YAML 配置文件路径，为空时从空配置开始。
+	Path string
+	// EnvPrefix 表示环境变量前缀，为空时不读取环境变量。
+	// 例如前缀 ZEUS 下，ZEUS_ETCD_ENDPOINTS 覆盖 etcd.endpoints，
+	// ZEUS_LOGGERS_WS_LEVEL 覆盖名为 ws 的日志配置的 level。
+	EnvPrefix string
+	// Overrides 表示命令行覆盖项，格式为 key.path=value，例如 etcd.dial_timeout=3s。
+	Overrides []string
+}
+
+// LoadConfig 按 ConfigSource 描述的顺序加载分层配置，YAML 文件中标量值里的 ${VAR} 与
+// ${VAR:-default} 会先被替换为环境变量的值。
+func LoadConfig(src ConfigSource) (Config, error) {
+	root, err := loadConfigNode(src.Path)
+	if err != nil {
+		return Config{}, err
+	}
+	if src.EnvPrefix != "" {
+		applyEnv(root, src.EnvPrefix, os.Environ())
+	}
+	for _, override := range src.Overrides {
+		if err := applyOverride(root, override); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var cfg Config
+	if err := coerceLists(root, reflect.TypeFor[Config]()).Decode(&cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// RegisterFlags 在 fs 上注册配置相关的命令行参数：-config 指定配置文件路径，
+// -set key.path=value 覆盖单个配置项（可重复），需在 Init 前解析。
+func (a *BaseApplication) RegisterFlags(fs *flag.FlagSet) {
+	fs.Func("config", "path of the YAML config file", func(path string) error {
+		return a.SetConfigPath(path)
+	})
+	fs.Func("set", "override a config value, e.g. -set etcd.endpoints=a:2379,b:2379", func(override string) error {
+		if _, _, ok := strings.Cut(override, "="); !ok {
+			return fmt.Errorf("%w: %q", errInvalidOverride, override)
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.initializing || a.initialized || a.started {
+			return errConfigLocked
+		}
+		a.overrides = append(a.overrides, override)
+		return nil
+	})
+}
+
+// WithEnvPrefix 设置覆盖配置的环境变量前缀，例如 ZEUS。
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// interpolate 将 ${VAR} 与 ${VAR:-default} 替换为环境变量的值。
+func interpolate(raw string) string {
+	return interpolatePattern.ReplaceAllStringFunc(raw, func(match string) string {
+		groups := interpolatePattern.FindStringSubmatch(match)
+		if value, ok := os.LookupEnv(groups[1]); ok && value != "" {
+			return value
+		}
+		return groups[2]
+	})
+}
+
+// interpolateNode 替换解析后各标量值中的变量引用，变量值只会成为标量的内容，不会引入新的键。
+// 未加引号的标量按替换后的值重新推断类型。
+func interpolateNode(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		if !interpolatePattern.MatchString(node.Value) {
+			return
+		}
+		node.Value = interpolate(node.Value)
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+		return
+	}
+	for _, child := range node.Content {
+		interpolateNode(child)
+	}
+}
+
+// loadConfigNode 读取并解析 YAML 文件，返回根映射节点。
+func loadConfigNode(path string) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if path == "" {
+		return root, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	interpolateNode(&doc)
+	if len(doc.Content) == 0 {
+		return root, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("app: config root of %s must be a mapping", path)
+	}
+	return doc.Content[0], nil
+}
+
+// applyEnv 将带前缀的环境变量覆盖到配置树。变量名去掉前缀后按 "_" 切分，
+// 并优先匹配配置树中已有的键（键本身可含 "_"），未命中的剩余部分作为单个键写入。
+func applyEnv(root *yaml.Node, prefix string, environ []string) {
+	prefix = strings.ToUpper(prefix) + "_"
+	sort.Strings(environ)
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+			continue
+		}
+		tokens := strings.Split(strings.ToLower(key[len(prefix):]), "_")
+		setEnvPath(root, tokens, value, true)
+	}
+}
+
+func setEnvPath(node *yaml.Node, tokens []string, value string, root bool) {
+	for n := len(tokens); n >= 1; n-- {
+		child := lookupChild(node, strings.Join(tokens[:n], "_"), true)
+		if child == nil {
+			continue
+		}
+		if n == len(tokens) {
+			setValue(child, value)
+			return
+		}
+		if child.Kind == yaml.MappingNode || child.Kind == yaml.SequenceNode {
+			setEnvPath(child, tokens[n:], value, false)
+			return
+		}
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	// 根节点下首段作为配置段名称，其余部分作为配置段内的键。
+	if root && len(tokens) > 1 {
+		section := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		appendMapping(node, tokens[0], section)
+		setEnvPath(section, tokens[1:], value, false)
+		return
+	}
+	leaf := &yaml.Node{}
+	setValue(leaf, value)
+	appendMapping(node, strings.Join(tokens, "_"), leaf)
+}
+
+// applyOverride 将 key.path=value 形式的覆盖项写入配置树，路径中缺失的映射会被创建。
+func applyOverride(root *yaml.Node, override string) error {
+	path, value, ok := strings.Cut(override, "=")
+	path = strings.TrimSpace(path)
+	if !ok || path == "" {
+		return fmt.Errorf("%w: %q", errInvalidOverride, override)
+	}
+
+	node := root
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		child := lookupChild(node, segment, false)
+		if child == nil {
+			if node.Kind != yaml.MappingNode {
+				return fmt.Errorf("%w: %q has no element %q", errInvalidOverride, strings.Join(segments[:i], "."), segment)
+			}
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			appendMapping(node, segment, child)
+		}
+		node = child
+	}
+	setValue(node, value)
+	return nil
+}
+
+// lookupChild 在映射中按键、在序列中按下标或元素的 name 字段查找子节点。
+func lookupChild(node *yaml.Node, key string, fold bool) *yaml.Node {
+	equal := func(a, b string) bool {
+		if fold {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if equal(node.Content[i].Value, key) {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if idx, err := strconv.Atoi(key); err == nil && idx >= 0 && idx < len(node.Content) {
+			return node.Content[idx]
+		}
+		for _, item := range node.Content {
+			if name := lookupChild(item, "name", false); name != nil && name.Kind == yaml.ScalarNode && equal(name.Value, key) {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+func appendMapping(node *yaml.Node, key string, value *yaml.Node) {
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// setValue 以 YAML 语法解析 raw 并替换节点内容；目标为序列而 raw 为普通标量时按逗号切分，
+// 不存在的列表字段在解码时由 coerceLists 按目标类型切分。
+func setValue(node *yaml.Node, raw string) {
+	parsed := &yaml.Node{Kind: yaml.ScalarNode, Value: raw}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err == nil && len(doc.Content) > 0 {
+		parsed = doc.Content[0]
+	}
+
+	if node.Kind == yaml.SequenceNode && parsed.Kind == yaml.ScalarNode {
+		splitList(node, raw)
+		return
+	}
+	*node = *parsed
+}
+
+var (
+	yamlNodeType    = reflect.TypeFor[yaml.Node]()
+	unmarshalerType = reflect.TypeFor[yaml.Unmarshaler]()
+)
+
+// coerceLists 按解码目标的类型将标量转换为序列：目标为标量元素的切片时按逗号切分，
+// 使环境变量与命令行覆盖项可以为文件中不存在的列表字段赋值。返回副本，node 保持不变。
+func coerceLists(node *yaml.Node, t reflect.Type) *yaml.Node {
+	node = copyNode(node)
+	coerceNode(node, t)
+	return node
+}
+
+func coerceNode(node *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == yamlNodeType || t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			coerceNode(child, t)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind == yaml.MappingNode {
+			fields, inline := structFields(t)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if ft, ok := fields[node.Content[i].Value]; ok {
+					coerceNode(node.Content[i+1], ft)
+				} else if inline != nil {
+					coerceNode(node.Content[i+1], inline)
+				}
+			}
+		}
+	case reflect.Map:
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				coerceNode(node.Content[i], t.Elem())
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		switch {
+		case node.Kind == yaml.SequenceNode:
+			for _, item := range node.Content {
+				coerceNode(item, t.Elem())
+			}
+		case node.Kind == yaml.ScalarNode && node.ShortTag() != "!!null" && isScalarKind(t.Elem().Kind()):
+			splitList(node, node.Value)
+		}
+	}
+}
+
+// structFields 返回结构体按 YAML 键索引的字段类型，以及内联映射的元素类型。
+func structFields(t reflect.Type) (map[string]reflect.Type, reflect.Type) {
+	fields := make(map[string]reflect.Type, t.NumField())
+	var inline reflect.Type
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if slices.Contains(strings.Split(opts, ","), "inline") {
+			switch f.Type.Kind() {
+			case reflect.Map:
+				inline = f.Type.Elem()
+			case reflect.Struct:
+				sub, subInline := structFields(f.Type)
+				maps.Copy(fields, sub)
+				if subInline != nil {
+					inline = subInline
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields, inline
+}
+
+// isScalarKind 判断列表元素是否为可由逗号切分得到的标量，[]byte 不在其列。
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitList 将 node 替换为 raw 按逗号切分得到的序列。
+func splitList(node *yaml.Node, raw string) {
+	seq := yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: node.Line, Column: node.Column}
+	for _, item := range strings.Split(raw, ",") {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: strings.TrimSpace(item)})
+	}
+	*node = seq
+}
+
+func copyNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	cp := *node
+	if node.Content != nil {
+		cp.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			cp.Content[i] = copyNode(child)
+		}
+	}
+	return &cp
+}
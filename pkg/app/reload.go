@@ -0,0 +1,252 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/ticker"
+)
+
+// loggersSection 表示日志配置段在 Diff 结果中的名称。
+const loggersSection = "loggers"
+
+var errNotInitialized = errors.New("app: application is not initialized")
+
+// PhaseReload 表示配置热更新阶段。
+const PhaseReload Phase = "reload"
+
+// Reloadable 定义支持配置热更新的模块或服务。
+type Reloadable interface {
+	// Reload 在与组件 ID 同名的配置段发生变化时调用。
+	Reload(ctx context.Context, change ConfigChange) error
+}
+
+// ConfigChange 描述单个配置段的变更。
+type ConfigChange struct {
+	// Section 表示发生变化的配置段名称。
+	Section string
+	// Old 表示变更前的配置段。
+	Old ConfigDecoder
+	// New 表示变更后的配置段。
+	New ConfigDecoder
+}
+
+// WithConfigWatch 按 interval 轮询配置文件，文件修改后自动触发 Reload。
+func WithConfigWatch(interval time.Duration) Option {
+	return func(o *options) {
+		o.watchInterval = interval
+	}
+}
+
+// Diff 返回 other 相对 c 发生变化的配置段名称（按名称排序），日志配置段记为 "loggers"。
+func (c Config) Diff(other Config) []string {
+	var changed []string
+	if !reflect.DeepEqual(c.Loggers, other.Loggers) || !reflect.DeepEqual(c.LoggerLevels, other.LoggerLevels) {
+		changed = append(changed, loggersSection)
+	}
+
+	names := make(map[string]struct{}, len(c.Sections)+len(other.Sections))
+	for name := range c.Sections {
+		names[name] = struct{}{}
+	}
+	for name := range other.Sections {
+		names[name] = struct{}{}
+	}
+	for name := range names {
+		if !sectionEqual(c, other, name) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Reload 重新加载配置并下发变更：日志配置中的等级与等级覆盖直接在已注册的 Logger 上生效，
+// 其余配置段交给同名的 Reloadable 组件。日志文件路径等其他日志配置需重启生效。
+func (a *BaseApplication) Reload(ctx context.Context) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	a.mu.RLock()
+	if !a.initialized {
+		a.mu.RUnlock()
+		return errNotInitialized
+	}
+	src := a.configSource()
+	old := a.config
+	order := append([]component(nil), a.order...)
+	a.mu.RUnlock()
+
+	// 在读取前记录修改时间，读取期间的修改会在下次轮询时重新加载
+	modTime := configFileModTime(src.Path)
+	cfg, err := LoadConfig(src)
+	if err != nil {
+		return err
+	}
+	a.markConfigFile(modTime)
+
+	changed := old.Diff(cfg)
+	if len(changed) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	a.config = cfg
+	a.mu.Unlock()
+
+	var levelErr error
+	if slices.Contains(changed, loggersSection) {
+		levelErr = applyLoggerLevels(old, cfg)
+	}
+
+	errs := make([]error, len(order))
+	for i, c := range order {
+		r, ok := c.(Reloadable)
+		if !ok || !slices.Contains(changed, c.ID()) {
+			continue
+		}
+		errs[i] = r.Reload(ctx, ConfigChange{
+			Section: c.ID(),
+			Old:     old.Section(c.ID()),
+			New:     cfg.Section(c.ID()),
+		})
+	}
+
+	var lifecycleErr LifecycleError
+	a.record(ctx, &lifecycleErr, PhaseReload, order, errs)
+	return joinErrors(levelErr, lifecycleErr.err())
+}
+
+// watchConfig 启动配置文件轮询，调用方需保证应用已处于运行状态。
+func (a *BaseApplication) watchConfig() {
+	a.mu.Lock()
+	path := a.configPath
+	a.mu.Unlock()
+	if a.opts.watchInterval <= 0 || path == "" {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := ticker.New(a.opts.watchInterval, func() {
+		if !a.configFileChanged(path) {
+			return
+		}
+		if err := a.Reload(ctx); err != nil {
+			logger.Get("app").Error("config reload failed",
+				logger.Field{Key: "path", Value: path},
+				logger.Field{Key: "error", Value: err},
+			)
+		}
+	})
+	future := conc.Go(func() (struct{}, error) {
+		return struct{}{}, t.Start(ctx)
+	})
+
+	a.mu.Lock()
+	a.unwatch = func() {
+		cancel()
+		_ = future.Err()
+	}
+	a.mu.Unlock()
+}
+
+// unwatchConfig 停止配置文件轮询并等待其退出。
+func (a *BaseApplication) unwatchConfig() {
+	a.mu.Lock()
+	unwatch := a.unwatch
+	a.unwatch = nil
+	a.mu.Unlock()
+
+	if unwatch != nil {
+		unwatch()
+	}
+}
+
+// markConfigFile 记录已加载的配置文件的修改时间。
+func (a *BaseApplication) markConfigFile(modTime time.Time) {
+	a.mu.Lock()
+	a.configModTime = modTime
+	a.mu.Unlock()
+}
+
+// configFileModTime 返回配置文件的修改时间，未设置路径或无法读取时返回零值。
+func configFileModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// configFileChanged 判断配置文件的修改时间是否晚于上次加载。
+func (a *BaseApplication) configFileChanged(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return !info.ModTime().Equal(a.configModTime)
+}
+
+// configSource 返回当前的分层配置来源，调用方需持有 a.mu。
+func (a *BaseApplication) configSource() ConfigSource {
+	return ConfigSource{
+		Path:      a.configPath,
+		EnvPrefix: a.opts.envPrefix,
+		Overrides: append([]string(nil), a.overrides...),
+	}
+}
+
+// applyLoggerLevels 将新配置中等级发生变化的日志等级应用到已注册的同名 Logger。
+// 等级覆盖发生变化时，按新配置重新计算全部具名日志的等级并重新应用覆盖。
+func applyLoggerLevels(old, updated Config) error {
+	overridesChanged := !reflect.DeepEqual(old.LoggerLevels, updated.LoggerLevels)
+	previous := make(map[string]string, len(old.Loggers))
+	for _, item := range old.Loggers {
+		previous[strings.TrimSpace(item.Name)] = item.Level
+	}
+
+	var errs []error
+	for _, item := range updated.Loggers {
+		name := strings.TrimSpace(item.Name)
+		prev, ok := previous[name]
+		if !ok || (prev == item.Level && !overridesChanged) {
+			continue
+		}
+		level, err := logger.ParseLevel(item.Level)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ctl, ok := logger.Get(name).(logger.LevelController); ok {
+			ctl.SetLevel(level)
+		}
+	}
+	if overridesChanged {
+		logger.ResetLevelPatterns()
+		errs = append(errs, logger.ApplyLevelOverrides(updated.LoggerLevels))
+	}
+	return errors.Join(errs...)
+}
+
+func sectionEqual(a, b Config, name string) bool {
+	var left, right any
+	if err := a.Decode(name, &left); err != nil {
+		return false
+	}
+	if err := b.Decode(name, &right); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
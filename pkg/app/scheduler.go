@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"maps"
+
+	"github.com/lk2023060901/zeus-go/pkg/scheduler"
+)
+
+// SchedulerID 表示调度器服务的 ID，同时也是其配置段名称。
+const SchedulerID = "scheduler"
+
+// SchedulerService 将调度器接入应用生命周期：Start 开始调度，Drain 等待执行中的任务完成，
+// Stop 停止调度。配置段中的默认任务选项与按名称配置的任务选项在 Init 前与热更新时生效，
+// 时区等其他配置需在创建调度器时指定。
+//
+//	sched, _ := scheduler.New(scheduler.DefaultConfig())
+//	_ = application.RegisterService(app.NewSchedulerService(sched))
+type SchedulerService struct {
+	sched    *scheduler.Scheduler
+	requires []string
+	// base 表示创建时的调度器配置，配置段在其基础上解码，配置段不存在时恢复为该配置
+	base scheduler.Config
+}
+
+// NewSchedulerService 创建调度器服务，requires 为任务依赖的组件 ID。
+func NewSchedulerService(s *scheduler.Scheduler, requires ...string) *SchedulerService {
+	return &SchedulerService{sched: s, requires: requires, base: s.Config()}
+}
+
+// Scheduler 返回被管理的调度器。
+func (s *SchedulerService) Scheduler() *scheduler.Scheduler {
+	return s.sched
+}
+
+// ID 返回服务 ID。
+func (s *SchedulerService) ID() string {
+	return SchedulerID
+}
+
+// Requires 返回依赖的组件 ID 列表。
+func (s *SchedulerService) Requires() []string {
+	return s.requires
+}
+
+// Configure 从 scheduler 配置段读取任务选项。
+func (s *SchedulerService) Configure(dec ConfigDecoder) error {
+	return s.apply(dec)
+}
+
+// Init 不做任何处理，调度器在创建时已完成初始化。
+func (s *SchedulerService) Init(_ context.Context) error {
+	return nil
+}
+
+// Start 开始调度任务。
+func (s *SchedulerService) Start(_ context.Context) error {
+	s.sched.Start()
+	return nil
+}
+
+// Drain 停止调度新的任务执行，等待执行中的任务完成或 ctx 结束。
+func (s *SchedulerService) Drain(ctx context.Context) error {
+	return s.sched.Drain(ctx)
+}
+
+// Stop 停止调度并等待执行中的任务完成，ctx 结束时返回 ctx 的错误。
+func (s *SchedulerService) Stop(ctx context.Context) error {
+	// 调度器未运行时 Stop 返回 context.Background()，其 Done 为 nil
+	done := s.sched.Stop().Done()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reload 在 scheduler 配置段变化时更新默认任务选项与按名称配置的任务选项。
+func (s *SchedulerService) Reload(_ context.Context, change ConfigChange) error {
+	return s.apply(change.New)
+}
+
+func (s *SchedulerService) apply(dec ConfigDecoder) error {
+	cfg := s.base
+	cfg.Jobs = maps.Clone(s.base.Jobs)
+	if err := dec.Decode(&cfg); err != nil {
+		return err
+	}
+	s.sched.ApplyConfig(&cfg)
+	return nil
+}
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/scheduler"
+	"github.com/lk2023060901/zeus-go/pkg/service"
+)
+
+const (
+	// PhaseRun 表示服务启动后的运行期。
+	PhaseRun Phase = "run"
+	// PhaseRestart 表示监管者重启服务的阶段。
+	PhaseRestart Phase = "restart"
+)
+
+// defaultResetAfter 表示 SupervisorConfig.ResetAfter 为 0 时使用的稳定运行时长。
+const defaultResetAfter = 10 * time.Minute
+
+// SupervisorConfig 定义服务监管配置。
+type SupervisorConfig struct {
+	// MaxRestarts 表示单个服务允许的最大重启次数，超过后升级为应用整体关闭。
+	MaxRestarts int
+	// Backoff 表示两次重启之间的退避策略，仅使用其中的退避字段。
+	Backoff scheduler.JobOptions
+	// ResetAfter 表示服务稳定运行多久后重置重启次数与退避，0 表示 10 分钟，负数表示从不重置。
+	ResetAfter time.Duration
+}
+
+// resetAfter 返回实际使用的稳定运行时长，不重置时返回 0。
+func (c SupervisorConfig) resetAfter() time.Duration {
+	switch {
+	case c.ResetAfter < 0:
+		return 0
+	case c.ResetAfter == 0:
+		return defaultResetAfter
+	default:
+		return c.ResetAfter
+	}
+}
+
+// WithSupervisor 启用服务监管：实现 service.Supervised 的服务在运行期上报故障后，
+// 应用会按退避策略将其停止并重新启动，重启次数耗尽后触发应用关闭。
+// 被重启服务的依赖者不会随之重启。
+func WithSupervisor(cfg SupervisorConfig) Option {
+	return func(o *options) {
+		o.supervisor = &cfg
+	}
+}
+
+// supervise 为所有可被监管的组件启动监管协程，调用方需保证应用已处于运行状态。
+func (a *BaseApplication) supervise(order []component) {
+	cfg := a.opts.supervisor
+	if cfg == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.mu.Lock()
+	a.superviseCancel = cancel
+	a.mu.Unlock()
+
+	for _, c := range order {
+		s, ok := c.(service.Supervised)
+		if !ok {
+			continue
+		}
+		a.superviseWG.Add(1)
+		go func() {
+			defer a.superviseWG.Done()
+			a.superviseLoop(ctx, *cfg, s)
+		}()
+	}
+}
+
+// unsupervise 停止所有监管协程并等待其退出。
+func (a *BaseApplication) unsupervise() {
+	a.mu.Lock()
+	cancel := a.superviseCancel
+	a.superviseCancel = nil
+	a.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	a.superviseWG.Wait()
+}
+
+func (a *BaseApplication) superviseLoop(ctx context.Context, cfg SupervisorConfig, s service.Supervised) {
+	log := logger.Get("app")
+	backoff := scheduler.NewBackoff(cfg.Backoff)
+	failures := s.Failures()
+	restarts := 0
+	// running 表示服务最近一次进入运行状态的时间
+	running := time.Now()
+
+	for {
+		var cause error
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-failures:
+			if !ok {
+				return
+			}
+			cause = err
+		}
+		if resetAfter := cfg.resetAfter(); resetAfter > 0 && restarts > 0 && time.Since(running) >= resetAfter {
+			restarts = 0
+			backoff.Reset()
+		}
+
+		for cause != nil {
+			a.setComponentState(s.ID(), StateFailed)
+			a.componentFailed(ctx, &ComponentError{ID: s.ID(), Phase: PhaseRun, Err: cause})
+			if restarts >= cfg.MaxRestarts {
+				a.fail(fmt.Errorf("app: service %s exceeded %d restarts: %w", s.ID(), cfg.MaxRestarts, cause))
+				return
+			}
+			restarts++
+
+			delay := backoff.Next(restarts)
+			log.Warn("service failed, restarting",
+				logger.Field{Key: "service", Value: s.ID()},
+				logger.Field{Key: "attempt", Value: restarts},
+				logger.Field{Key: "backoff", Value: delay},
+				logger.Field{Key: "error", Value: cause},
+			)
+			if !sleepContext(ctx, delay) {
+				return
+			}
+
+			cause = a.restart(ctx, s)
+			if ctx.Err() != nil {
+				return
+			}
+			if cause == nil {
+				running = time.Now()
+				a.setComponentState(s.ID(), StateRunning)
+			}
+		}
+		failures = s.Failures()
+	}
+}
+
+// restart 在 Stop 与 Start 超时约束下重启服务。
+// 监管被取消时等待进行中的 Stop 或 Start 返回，保证应用停止该服务前重启已经结束。
+func (a *BaseApplication) restart(ctx context.Context, s service.Supervised) error {
+	if err := a.invokeAwait(ctx, s.ID(), a.componentTimeout(s.ID(), PhaseStop), s.Stop); err != nil {
+		a.componentFailed(ctx, &ComponentError{ID: s.ID(), Phase: PhaseRestart, Err: err})
+	}
+	return a.invokeAwait(ctx, s.ID(), a.componentTimeout(s.ID(), PhaseStart), s.Start)
+}
+
+// invokeAwait 与 invokeComponent 相同，但 ctx 取消时会等待 fn 响应取消并返回，
+// 仅在 timeout 到期时放弃等待。
+func (a *BaseApplication) invokeAwait(ctx context.Context, id string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	fnCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		fnCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+	if err := a.awaitAbandoned(fnCtx, id); err != nil {
+		return err
+	}
+
+	future := conc.Go(func() (struct{}, error) {
+		return struct{}{}, fn(fnCtx)
+	})
+	select {
+	case <-future.Inner():
+		return future.Err()
+	case <-fnCtx.Done():
+		if ctx.Err() != nil {
+			<-future.Inner()
+		} else {
+			a.abandon(id, future)
+		}
+		return fnCtx.Err()
+	}
+}
+
+// componentFailed 触发 OnComponentFailed 钩子。
+func (a *BaseApplication) componentFailed(ctx context.Context, err *ComponentError) {
+	a.mu.RLock()
+	hooks := append([]Hooks(nil), a.hooks...)
+	a.mu.RUnlock()
+
+	for _, h := range hooks {
+		if h.OnComponentFailed != nil {
+			h.OnComponentFailed(ctx, err)
+		}
+	}
+}
+
+// fail 记录导致应用关闭的故障并异步触发关闭流程。
+func (a *BaseApplication) fail(err error) {
+	a.mu.Lock()
+	if a.failure == nil {
+		a.failure = err
+	}
+	a.mu.Unlock()
+
+	go func() {
+		_ = a.Shutdown(context.Background())
+	}()
+}
+
+// sleepContext 等待 d 或 ctx 取消，ctx 取消时返回 false。
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidRequirement  = errors.New("app: invalid requirement")
+	errInvalidVersion      = errors.New("app: invalid version")
+	errIncompatibleVersion = errors.New("app: incompatible dependency version")
+)
+
+// constraintOps 列出版本约束支持的运算符，较长的运算符在前以便优先匹配。
+var constraintOps = []string{">=", "<=", "==", "!=", ">", "<", "=", "~", "^"}
+
+// Dependency 表示 Requires 中的一项依赖声明，格式为 ID 后接可选的版本约束，
+// 例如 "storage"、"storage>=1.2.0"、"storage>=1.2.0,<2.0.0"、"storage^1.2"。
+type Dependency struct {
+	// ID 表示被依赖组件的 ID。
+	ID string
+	// Constraint 表示版本约束，为空时不限制版本。
+	Constraint string
+}
+
+// ParseDependency 解析一项依赖声明。版本约束由逗号分隔的子句组成，子句之间为“且”关系；
+// 支持 =、==、!=、>、>=、<、<=，以及 ~1.2.3（>=1.2.3,<1.3.0）与 ^1.2.3（>=1.2.3,<2.0.0）。
+// ~ 与 ^ 不匹配预发布版本，除非约束本身带有预发布标识且与版本的主、次、修订号相同，
+// 例如 ^1.2.0-rc.1 匹配 1.2.0-rc.2，但不匹配 1.3.0-rc.1。
+func ParseDependency(raw string) (Dependency, error) {
+	raw = strings.TrimSpace(raw)
+	idx := strings.IndexAny(raw, "<>=!~^")
+	if idx < 0 {
+		return Dependency{ID: raw}, nil
+	}
+
+	dep := Dependency{
+		ID:         strings.TrimSpace(raw[:idx]),
+		Constraint: strings.TrimSpace(raw[idx:]),
+	}
+	if dep.ID == "" {
+		return Dependency{}, fmt.Errorf("%w: %q has no id", errInvalidRequirement, raw)
+	}
+	if _, err := parseConstraint(dep.Constraint); err != nil {
+		return Dependency{}, fmt.Errorf("%w: %q: %v", errInvalidRequirement, raw, err)
+	}
+	return dep, nil
+}
+
+// String 返回依赖声明的原始格式。
+func (d Dependency) String() string {
+	return d.ID + d.Constraint
+}
+
+// Allows 判断 version 是否满足版本约束，约束为空时总是满足。
+func (d Dependency) Allows(version string) (bool, error) {
+	if d.Constraint == "" {
+		return true, nil
+	}
+	clauses, err := parseConstraint(d.Constraint)
+	if err != nil {
+		return false, err
+	}
+	v, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range clauses {
+		if !c.allows(v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// semver 表示语义化版本号，构建元数据被忽略。
+type semver struct {
+	major, minor, patch int
+	pre                 string
+}
+
+// parseVersion 解析 1.2.3、v1.2.3、1.2 与 1.2.3-rc.1 等格式的版本号，缺省部分视为 0。
+func parseVersion(raw string) (semver, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(raw), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, _ := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if s == "" || len(parts) > 3 {
+		return semver{}, fmt.Errorf("%w: %q", errInvalidVersion, raw)
+	}
+	nums := [3]int{}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, fmt.Errorf("%w: %q", errInvalidVersion, raw)
+		}
+		nums[i] = n
+	}
+	return semver{major: nums[0], minor: nums[1], patch: nums[2], pre: pre}, nil
+}
+
+// compare 按语义化版本规则比较 v 与 o，返回 -1、0 或 1。
+func (v semver) compare(o semver) int {
+	for _, d := range [3]int{v.major - o.major, v.minor - o.minor, v.patch - o.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	return comparePrerelease(v.pre, o.pre)
+}
+
+// comparePrerelease 比较预发布标识：无预发布标识的版本更大，
+// 数字标识按数值比较且小于字母标识。
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// clause 表示单个版本约束子句。
+type clause struct {
+	op      string
+	version semver
+	// stable 为 true 时仅匹配与 version 主、次、修订号相同的预发布版本
+	stable bool
+}
+
+func (c clause) allows(v semver) bool {
+	if c.stable && v.pre != "" && (c.version.pre == "" ||
+		v.major != c.version.major || v.minor != c.version.minor || v.patch != c.version.patch) {
+		return false
+	}
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "=", "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+// parseConstraint 将逗号分隔的约束解析为子句列表，~ 与 ^ 会展开为上下界两个子句。
+func parseConstraint(raw string) ([]clause, error) {
+	var clauses []clause
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		op := ""
+		for _, candidate := range constraintOps {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return nil, fmt.Errorf("missing operator in %q", part)
+		}
+		v, err := parseVersion(part[len(op):])
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case "~":
+			upper := semver{major: v.major, minor: v.minor + 1}
+			clauses = append(clauses, clause{op: ">=", version: v, stable: true}, clause{op: "<", version: upper})
+		case "^":
+			upper := semver{major: v.major + 1}
+			if v.major == 0 {
+				upper = semver{minor: v.minor + 1}
+			}
+			clauses = append(clauses, clause{op: ">=", version: v, stable: true}, clause{op: "<", version: upper})
+		default:
+			clauses = append(clauses, clause{op: op, version: v})
+		}
+	}
+	return clauses, nil
+}
@@ -9,9 +9,9 @@ import (
 // Config 时钟配置
 type Config struct {
 	// ResetHour 每日重置小时（0-23），默认 5 表示凌晨5点
-	ResetHour int
+	ResetHour int `yaml:"reset_hour"`
 	// Timezone 时区名称，默认 "Asia/Shanghai"
-	Timezone string
+	Timezone string `yaml:"timezone"`
 }
 
 // DefaultConfig 返回默认配置
@@ -24,4 +24,7 @@ var (
 
 	// ErrInvalidPoolSize 无效的池大小
 	ErrInvalidPoolSize = errors.New("invalid pool size: must be positive")
+
+	// ErrPoolDraining 池正在排空，不再接收新任务
+	ErrPoolDraining = errors.New("pool is draining")
 )
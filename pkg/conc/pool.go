@@ -17,9 +17,11 @@
 package conc
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	ants "github.com/panjf2000/ants/v2"
@@ -27,10 +29,18 @@ import (
 	"github.com/lk2023060901/zeus-go/pkg/generic"
 )
 
+// drainPollInterval is the interval at which Drain checks for running workers.
+const drainPollInterval = 10 * time.Millisecond
+
 // A goroutine pool
 type Pool[T any] struct {
 	inner *ants.Pool
 	opt   *poolOption
+	// draining rejects new submissions once Drain is called
+	draining atomic.Bool
+	// submitting counts the submissions in flight, so Drain does not miss a task
+	// that passed the draining check but is not running yet
+	submitting atomic.Int64
 }
 
 // NewPool returns a goroutine pool.
@@ -63,8 +73,16 @@ func NewDefaultPool[T any]() *Pool[T] {
 // executes it asynchronously.
 // This will block if the pool has finite workers and no idle worker.
 // NOTE: As now golang doesn't support the member method being generic, we use Future[any]
+// Submissions are rejected with ErrPoolDraining after Drain is called.
 func (pool *Pool[T]) Submit(method func() (T, error)) *Future[T] {
 	future := newFuture[T]()
+	pool.submitting.Add(1)
+	defer pool.submitting.Add(-1)
+	if pool.draining.Load() {
+		future.err = ErrPoolDraining
+		close(future.ch)
+		return future
+	}
 	err := pool.inner.Submit(func() {
 		defer close(future.ch)
 		defer func() {
@@ -106,6 +124,11 @@ func (pool *Pool[T]) Free() int {
 	return pool.inner.Free()
 }
 
+// IsDraining returns whether Drain has been called
+func (pool *Pool[T]) IsDraining() bool {
+	return pool.draining.Load()
+}
+
 func (pool *Pool[T]) IsClosed() bool {
 	return pool.inner.IsClosed()
 }
@@ -118,6 +141,24 @@ func (pool *Pool[T]) ReleaseTimeout(timeout time.Duration) error {
 	return pool.inner.ReleaseTimeout(timeout)
 }
 
+// Drain stops accepting new tasks and waits for the running ones to finish,
+// returns ctx.Err() if ctx is done first.
+// The pool is not released by Drain, call Release afterwards to free the workers.
+func (pool *Pool[T]) Drain(ctx context.Context) error {
+	pool.draining.Store(true)
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for pool.submitting.Load() > 0 || pool.inner.Running() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
 func (pool *Pool[T]) Resize(size int) error {
 	if pool.opt.preAlloc {
 		return ErrCannotResizePreAlloc
@@ -17,6 +17,7 @@
 package conc
 
 import (
+	"context"
 	"runtime"
 	"testing"
 	"time"
@@ -75,6 +76,31 @@ func TestPoolResize(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestPoolDrain(t *testing.T) {
+	pool := NewPool[any](2)
+	release := make(chan struct{})
+	future := pool.Submit(func() (any, error) {
+		<-release
+		return 1, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Drain(ctx), context.DeadlineExceeded)
+	assert.True(t, pool.IsDraining())
+	assert.False(t, pool.IsClosed())
+	assert.ErrorIs(t, pool.Submit(func() (any, error) { return nil, nil }).Err(), ErrPoolDraining)
+
+	close(release)
+	assert.NoError(t, pool.Drain(context.Background()))
+	assert.Equal(t, 1, future.Value())
+	assert.Equal(t, 0, pool.Running())
+	assert.False(t, pool.IsClosed())
+
+	pool.Release()
+	assert.True(t, pool.IsClosed())
+}
+
 func TestPoolWithPanic(t *testing.T) {
 	pool := NewPool[any](1, WithConcealPanic(true))
 
@@ -145,6 +145,11 @@ func (elector *Elector) Key() string {
 	return elector.election.Key()
 }
 
+// Done 返回会话结束通道，会话租约过期或被关闭后通道关闭，此后选举器不再可用
+func (elector *Elector) Done() <-chan struct{} {
+	return elector.session.Done()
+}
+
 // Close 关闭选举器
 func (elector *Elector) Close() error {
 	return elector.session.Close()
@@ -0,0 +1,302 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/service"
+)
+
+// campaigner 抽象 LeaderService 依赖的选举操作，*Elector 实现了该接口
+type campaigner interface {
+	Campaign(ctx context.Context, value string) error
+	Resign(ctx context.Context) error
+	Done() <-chan struct{}
+	Close() error
+}
+
+// LeaderService 仅在当前进程持有 Leader 身份期间运行被包装的服务：
+// 竞选成功后启动服务，会话丢失或主动 Resign 后停止服务并重新参与竞选。
+// 适用于需要在多个副本间保证唯一运行实例的集群单例服务。
+type LeaderService struct {
+	inner  service.Service
+	prefix string
+	opts   leaderOptions
+
+	newCampaigner func() (campaigner, error)
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	loop     *conc.Future[struct{}]
+	current  campaigner
+	leading  bool
+	resignCh chan struct{}
+}
+
+// LeaderOption Leader 服务选项
+type LeaderOption func(*leaderOptions)
+
+type leaderOptions struct {
+	value         string
+	retryInterval time.Duration
+	stopTimeout   time.Duration
+	electionOpts  []ElectionOption
+	logger        logger.Logger
+}
+
+// WithLeaderValue 设置竞选时写入的值，默认为 "主机名-进程号"
+func WithLeaderValue(value string) LeaderOption {
+	return func(o *leaderOptions) {
+		o.value = value
+	}
+}
+
+// WithLeaderRetryInterval 设置竞选或启动失败后重新竞选前的等待时间，默认 1 秒
+func WithLeaderRetryInterval(d time.Duration) LeaderOption {
+	return func(o *leaderOptions) {
+		o.retryInterval = d
+	}
+}
+
+// WithLeaderStopTimeout 设置会话丢失后停止被包装服务的最长等待时间，默认 10 秒
+func WithLeaderStopTimeout(d time.Duration) LeaderOption {
+	return func(o *leaderOptions) {
+		o.stopTimeout = d
+	}
+}
+
+// WithLeaderElectionOptions 设置创建选举器时使用的选项，例如 WithElectionTTL
+func WithLeaderElectionOptions(opts ...ElectionOption) LeaderOption {
+	return func(o *leaderOptions) {
+		o.electionOpts = append(o.electionOpts, opts...)
+	}
+}
+
+// WithLeaderLogger 设置日志记录器
+func WithLeaderLogger(l logger.Logger) LeaderOption {
+	return func(o *leaderOptions) {
+		o.logger = l
+	}
+}
+
+// NewLeaderService 创建 Leader 服务，inner 仅在当前进程于 prefix 下竞选成功后运行
+func NewLeaderService(election *Election, prefix string, inner service.Service, opts ...LeaderOption) *LeaderService {
+	hostname, _ := os.Hostname()
+	options := leaderOptions{
+		value:         fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		retryInterval: time.Second,
+		stopTimeout:   10 * time.Second,
+		logger:        logger.Nop(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s := &LeaderService{
+		inner:    inner,
+		prefix:   prefix,
+		opts:     options,
+		resignCh: make(chan struct{}, 1),
+	}
+	s.newCampaigner = func() (campaigner, error) {
+		return election.NewElector(prefix, options.electionOpts...)
+	}
+	return s
+}
+
+// ID 返回被包装服务的 ID
+func (s *LeaderService) ID() string {
+	return s.inner.ID()
+}
+
+// Requires 返回被包装服务的依赖
+func (s *LeaderService) Requires() []string {
+	return s.inner.Requires()
+}
+
+// Init 初始化被包装的服务，所有副本都会执行
+func (s *LeaderService) Init(ctx context.Context) error {
+	return s.inner.Init(ctx)
+}
+
+// Start 在后台开始竞选，不等待竞选结果
+func (s *LeaderService) Start(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loop != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.loop = conc.Go(func() (struct{}, error) {
+		s.run(ctx)
+		return struct{}{}, nil
+	})
+	return nil
+}
+
+// Stop 停止竞选；若当前为 Leader，停止被包装的服务并让出 Leader 身份
+func (s *LeaderService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, loop := s.cancel, s.loop
+	s.cancel, s.loop = nil, nil
+	s.mu.Unlock()
+	if loop == nil {
+		return nil
+	}
+
+	cancel()
+	loop.Await()
+
+	s.mu.Lock()
+	current, leading := s.current, s.leading
+	s.current, s.leading = nil, false
+	s.mu.Unlock()
+	if current == nil {
+		return nil
+	}
+
+	var stopErr error
+	if leading {
+		stopErr = s.inner.Stop(ctx)
+		stopErr = errors.Join(stopErr, current.Resign(ctx))
+	}
+	return errors.Join(stopErr, current.Close())
+}
+
+// Drain 在当前为 Leader 时排空被包装的服务
+func (s *LeaderService) Drain(ctx context.Context) error {
+	d, ok := s.inner.(service.Drainer)
+	if !ok || !s.IsLeader() {
+		return nil
+	}
+	return d.Drain(ctx)
+}
+
+// IsLeader 返回当前进程是否持有 Leader 身份且被包装的服务正在运行
+func (s *LeaderService) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leading
+}
+
+// Resign 主动让出 Leader 身份：停止被包装的服务后重新参与竞选，非 Leader 时不做处理
+func (s *LeaderService) Resign() {
+	select {
+	case s.resignCh <- struct{}{}:
+	default:
+	}
+}
+
+// run 循环执行竞选、运行、让出，直到 ctx 取消
+func (s *LeaderService) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := s.term(ctx); err != nil && ctx.Err() == nil {
+			s.opts.logger.Warn("leader term ended",
+				logger.Field{Key: "service", Value: s.inner.ID()},
+				logger.Field{Key: "prefix", Value: s.prefix},
+				logger.Field{Key: "error", Value: err},
+			)
+			s.sleep(ctx)
+		}
+	}
+}
+
+// term 完成一次竞选：成为 Leader 后运行被包装的服务，直到会话丢失、主动让出或 ctx 取消。
+// ctx 取消时保留 Leader 状态，由 Stop 负责停止服务与让出
+func (s *LeaderService) term(ctx context.Context) error {
+	c, err := s.newCampaigner()
+	if err != nil {
+		return err
+	}
+	s.setCurrent(c, false)
+
+	if err := c.Campaign(ctx, s.opts.value); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.release(c, false)
+		return err
+	}
+	// 丢弃成为 Leader 之前积压的让出请求
+	select {
+	case <-s.resignCh:
+	default:
+	}
+
+	if err := s.inner.Start(ctx); err != nil {
+		s.release(c, true)
+		return err
+	}
+	s.setCurrent(c, true)
+	s.opts.logger.Info("leader elected, service started",
+		logger.Field{Key: "service", Value: s.inner.ID()},
+		logger.Field{Key: "prefix", Value: s.prefix},
+	)
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-c.Done():
+		err = fmt.Errorf("%w: session lost", ErrNotLeader)
+		stopErr := s.stopInner()
+		s.setCurrent(nil, false)
+		_ = c.Close()
+		return errors.Join(err, stopErr)
+	case <-s.resignCh:
+		stopErr := s.stopInner()
+		s.release(c, true)
+		s.opts.logger.Info("leader resigned, service stopped",
+			logger.Field{Key: "service", Value: s.inner.ID()},
+			logger.Field{Key: "prefix", Value: s.prefix},
+		)
+		return stopErr
+	}
+}
+
+// stopInner 在 stopTimeout 内停止被包装的服务，超时时记录日志
+func (s *LeaderService) stopInner() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.stopTimeout)
+	defer cancel()
+	err := s.inner.Stop(ctx)
+	if ctx.Err() != nil {
+		s.opts.logger.Error("leader service stop timed out",
+			logger.Field{Key: "service", Value: s.inner.ID()},
+			logger.Field{Key: "prefix", Value: s.prefix},
+			logger.Field{Key: "timeout", Value: s.opts.stopTimeout},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+	return err
+}
+
+// release 让出（如已当选）并关闭选举器
+func (s *LeaderService) release(c campaigner, elected bool) {
+	s.setCurrent(nil, false)
+	if elected {
+		_ = c.Resign(context.Background())
+	}
+	_ = c.Close()
+}
+
+func (s *LeaderService) setCurrent(c campaigner, leading bool) {
+	s.mu.Lock()
+	s.current, s.leading = c, leading
+	s.mu.Unlock()
+}
+
+func (s *LeaderService) sleep(ctx context.Context) {
+	timer := time.NewTimer(s.opts.retryInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,252 @@
+package etcd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCampaigner 由测试控制何时当选与会话何时丢失
+type fakeCampaigner struct {
+	grant    chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+	resigned bool
+	closed   bool
+}
+
+func newFakeCampaigner() *fakeCampaigner {
+	return &fakeCampaigner{
+		grant: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+func (c *fakeCampaigner) Campaign(ctx context.Context, _ string) error {
+	select {
+	case <-c.grant:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *fakeCampaigner) Resign(_ context.Context) error {
+	c.mu.Lock()
+	c.resigned = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeCampaigner) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *fakeCampaigner) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeCampaigner) state() (resigned, closed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resigned, c.closed
+}
+
+// countingService 统计启动与停止次数
+type countingService struct {
+	mu      sync.Mutex
+	starts  int
+	stops   int
+	running bool
+	// blockStop 为 true 时 Stop 阻塞到 ctx 结束
+	blockStop bool
+}
+
+func (s *countingService) ID() string                   { return "settlement" }
+func (s *countingService) Requires() []string           { return nil }
+func (s *countingService) Init(_ context.Context) error { return nil }
+
+func (s *countingService) Start(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.starts++
+	s.running = true
+	return nil
+}
+
+func (s *countingService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stops++
+	s.running = false
+	if s.blockStop {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *countingService) counts() (starts, stops int, running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.starts, s.stops, s.running
+}
+
+func newTestLeaderService(inner *countingService, opts ...LeaderOption) (*LeaderService, chan *fakeCampaigner) {
+	opts = append([]LeaderOption{WithLeaderRetryInterval(time.Millisecond)}, opts...)
+	s := NewLeaderService(nil, "/leader/settlement", inner, opts...)
+	created := make(chan *fakeCampaigner, 8)
+	s.newCampaigner = func() (campaigner, error) {
+		c := newFakeCampaigner()
+		created <- c
+		return c, nil
+	}
+	return s, created
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLeaderServiceLifecycle(t *testing.T) {
+	inner := &countingService{}
+	s, created := newTestLeaderService(inner)
+
+	if s.ID() != "settlement" {
+		t.Errorf("Expected ID=settlement, got %s", s.ID())
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// 未当选前不启动服务
+	first := <-created
+	if starts, _, _ := inner.counts(); starts != 0 {
+		t.Errorf("Expected no start before elected, got %d", starts)
+	}
+
+	// 当选后启动服务
+	close(first.grant)
+	waitFor(t, s.IsLeader)
+	if starts, _, running := inner.counts(); starts != 1 || !running {
+		t.Errorf("Expected service running after elected, starts=%d running=%v", starts, running)
+	}
+
+	// 会话丢失后停止服务并重新竞选
+	close(first.done)
+	second := <-created
+	waitFor(t, func() bool { return !s.IsLeader() })
+	if _, stops, running := inner.counts(); stops != 1 || running {
+		t.Errorf("Expected service stopped after session lost, stops=%d running=%v", stops, running)
+	}
+	if _, closed := first.state(); !closed {
+		t.Error("Expected lost elector to be closed")
+	}
+
+	// 再次当选后主动让出
+	close(second.grant)
+	waitFor(t, s.IsLeader)
+	s.Resign()
+	third := <-created
+	if resigned, closed := second.state(); !resigned || !closed {
+		t.Errorf("Expected resigned elector, resigned=%v closed=%v", resigned, closed)
+	}
+	if starts, stops, running := inner.counts(); starts != 2 || stops != 2 || running {
+		t.Errorf("Expected service stopped after resign, starts=%d stops=%d running=%v", starts, stops, running)
+	}
+
+	// 作为 Leader 停止时停止服务并让出
+	close(third.grant)
+	waitFor(t, s.IsLeader)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if resigned, closed := third.state(); !resigned || !closed {
+		t.Errorf("Expected elector resigned on stop, resigned=%v closed=%v", resigned, closed)
+	}
+	if starts, stops, running := inner.counts(); starts != 3 || stops != 3 || running {
+		t.Errorf("Expected service stopped on Stop, starts=%d stops=%d running=%v", starts, stops, running)
+	}
+}
+
+func TestLeaderServiceStopWhileCampaigning(t *testing.T) {
+	inner := &countingService{}
+	s, created := newTestLeaderService(inner)
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	c := <-created
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	resigned, closed := c.state()
+	if resigned || !closed {
+		t.Errorf("Expected elector closed without resign, resigned=%v closed=%v", resigned, closed)
+	}
+	if starts, stops, _ := inner.counts(); starts != 0 || stops != 0 {
+		t.Errorf("Expected service untouched, starts=%d stops=%d", starts, stops)
+	}
+}
+
+func TestLeaderServiceStopTimeout(t *testing.T) {
+	inner := &countingService{blockStop: true}
+	s, created := newTestLeaderService(inner, WithLeaderStopTimeout(20*time.Millisecond))
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	first := <-created
+	close(first.grant)
+	waitFor(t, s.IsLeader)
+
+	// 会话丢失后停止服务超时，仍会重新竞选
+	close(first.done)
+	var second *fakeCampaigner
+	select {
+	case second = <-created:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected campaign to restart after stop timeout")
+	}
+	if _, stops, _ := inner.counts(); stops != 1 {
+		t.Errorf("Expected service stopped once, got %d", stops)
+	}
+	if _, closed := first.state(); !closed {
+		t.Error("Expected lost elector to be closed")
+	}
+
+	// 主动让出时停止服务同样受超时限制
+	close(second.grant)
+	waitFor(t, s.IsLeader)
+	s.Resign()
+	select {
+	case <-created:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected campaign to restart after resign stop timeout")
+	}
+	if resigned, closed := second.state(); !resigned || !closed {
+		t.Errorf("Expected resigned elector, resigned=%v closed=%v", resigned, closed)
+	}
+	if _, stops, _ := inner.counts(); stops != 2 {
+		t.Errorf("Expected service stopped twice, got %d", stops)
+	}
+
+	inner.mu.Lock()
+	inner.blockStop = false
+	inner.mu.Unlock()
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
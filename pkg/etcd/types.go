@@ -91,27 +91,27 @@ type TxnResponse struct {
 // Config etcd 配置
 type Config struct {
 	// 连接配置
-	Endpoints   []string      // etcd 节点地址
-	DialTimeout time.Duration // 连接超时时间
+	Endpoints   []string      `yaml:"endpoints"`    // etcd 节点地址
+	DialTimeout time.Duration `yaml:"dial_timeout"` // 连接超时时间
 
 	// 认证配置
-	Username string // 用户名
-	Password string // 密码
+	Username string `yaml:"username"` // 用户名
+	Password string `yaml:"password"` // 密码
 
 	// TLS 配置
-	CertFile string // 客户端证书文件
-	KeyFile  string // 客户端私钥文件
-	CAFile   string // CA 证书文件
+	CertFile string `yaml:"cert_file"` // 客户端证书文件
+	KeyFile  string `yaml:"key_file"`  // 客户端私钥文件
+	CAFile   string `yaml:"ca_file"`   // CA 证书文件
 
 	// 其他配置
-	AutoSyncInterval   time.Duration // 自动同步间隔
-	MaxCallSendMsgSize int           // 最大发送消息大小
-	MaxCallRecvMsgSize int           // 最大接收消息大小
+	AutoSyncInterval   time.Duration `yaml:"auto_sync_interval"`     // 自动同步间隔
+	MaxCallSendMsgSize int           `yaml:"max_call_send_msg_size"` // 最大发送消息大小
+	MaxCallRecvMsgSize int           `yaml:"max_call_recv_msg_size"` // 最大接收消息大小
 
 	// 重试配置
-	EnableRetry   bool          // 启用重试
-	MaxRetries    int           // 最大重试次数
-	RetryInterval time.Duration // 重试间隔
+	EnableRetry   bool          `yaml:"enable_retry"`   // 启用重试
+	MaxRetries    int           `yaml:"max_retries"`    // 最大重试次数
+	RetryInterval time.Duration `yaml:"retry_interval"` // 重试间隔
 }
 
 // LockOption 锁选项
@@ -36,6 +36,12 @@ func (w *Watcher) WatchPrefix(ctx context.Context, prefix string, handler func(*
 	return w.watch(ctx, prefix, true, handler)
 }
 
+// WatchWithCloseHandler 与 Watch、WatchPrefix 相同，监听因错误结束（而非 ctx 取消或 StopWatch）
+// 时调用 onClose，调用方可据此记录日志并重新监听
+func (w *Watcher) WatchWithCloseHandler(ctx context.Context, key string, isPrefix bool, handler func(*WatchEvent), onClose func(error)) error {
+	return w.watchNotify(ctx, key, isPrefix, handler, onClose)
+}
+
 // WatchWithRevision 从指定版本开始监听
 func (w *Watcher) WatchWithRevision(ctx context.Context, key string, revision int64, handler func(*WatchEvent)) error {
 	opts := []clientv3.OpOption{
@@ -48,6 +54,11 @@ func (w *Watcher) WatchWithRevision(ctx context.Context, key string, revision in
 
 // watch 内部监听实现
 func (w *Watcher) watch(ctx context.Context, key string, isPrefix bool, handler func(*WatchEvent)) error {
+	return w.watchNotify(ctx, key, isPrefix, handler, nil)
+}
+
+// watchNotify 内部监听实现，onClose 不为空时在监听出错结束后调用
+func (w *Watcher) watchNotify(ctx context.Context, key string, isPrefix bool, handler func(*WatchEvent), onClose func(error)) error {
 	// 检查是否已经在监听
 	w.mu.RLock()
 	if _, exists := w.watches[key]; exists {
@@ -74,14 +85,16 @@ func (w *Watcher) watch(ctx context.Context, key string, isPrefix bool, handler
 
 	// 处理事件
 	conc.Go(func() (struct{}, error) {
-		defer func() {
-			w.mu.Lock()
-			delete(w.watches, key)
-			w.mu.Unlock()
-		}()
-
-		if err := w.processWatchEvents(watchCtx, key, watchCh, handler); err != nil {
-			// 监听出错，可以记录日志
+		err := w.processWatchEvents(watchCtx, key, watchCh, handler)
+		// ctx 取消或 StopWatch 导致的结束不视为出错
+		failed := err != nil && watchCtx.Err() == nil
+		cancel()
+		// 先移除监听记录，使 onClose 中可以重新监听同一个键
+		w.mu.Lock()
+		delete(w.watches, key)
+		w.mu.Unlock()
+		if failed && onClose != nil {
+			onClose(err)
 		}
 		return struct{}{}, nil
 	})
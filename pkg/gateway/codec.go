@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/lk2023060901/zeus-go/pkg/router"
+)
+
+var (
+	errNilMessage          = errors.New("gateway: message is nil")
+	errMessageRegistered   = errors.New("gateway: message already registered")
+	errMessageUnregistered = errors.New("gateway: message not registered")
+)
+
+// Codec 按注册的消息 ID 在帧与 proto 消息之间转换，供 router.Router 使用。
+// 客户端与服务端需约定相同的消息 ID。
+type Codec struct {
+	mu  sync.RWMutex
+	ids map[protoreflect.FullName]uint32
+	msg map[uint32]protoreflect.FullName
+}
+
+// NewCodec 创建帧编解码器。
+func NewCodec() *Codec {
+	return &Codec{
+		ids: make(map[protoreflect.FullName]uint32),
+		msg: make(map[uint32]protoreflect.FullName),
+	}
+}
+
+// Register 将消息 ID 绑定到 msg 的类型，msg 仅用于确定类型，可以传入 nil 指针，例如 (*pb.LoginReq)(nil)。
+func (c *Codec) Register(id uint32, msg proto.Message) error {
+	if msg == nil {
+		return errNilMessage
+	}
+	name := msg.ProtoReflect().Descriptor().FullName()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.msg[id]; exists {
+		return fmt.Errorf("%w: id %d", errMessageRegistered, id)
+	}
+	if _, exists := c.ids[name]; exists {
+		return fmt.Errorf("%w: %s", errMessageRegistered, name)
+	}
+	c.ids[name] = id
+	c.msg[id] = name
+	return nil
+}
+
+// Key 返回帧的消息 ID。
+func (c *Codec) Key(f Frame) (uint32, error) {
+	return f.ID, nil
+}
+
+// KeyOf 返回 proto 消息类型注册的消息 ID。
+func (c *Codec) KeyOf(msg proto.Message) (uint32, error) {
+	if msg == nil {
+		return 0, errNilMessage
+	}
+	name := msg.ProtoReflect().Descriptor().FullName()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	id, ok := c.ids[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", errMessageUnregistered, name)
+	}
+	return id, nil
+}
+
+// Decode 将帧的负载解码到 into。
+func (c *Codec) Decode(f Frame, into proto.Message) error {
+	return proto.Unmarshal(f.Payload, into)
+}
+
+// Encode 将 proto 消息编码为帧。
+func (c *Codec) Encode(msg proto.Message) (Frame, error) {
+	id, err := c.KeyOf(msg)
+	if err != nil {
+		return Frame{}, err
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return Frame{}, err
+	}
+	return Frame{ID: id, Payload: payload}, nil
+}
+
+var _ router.Codec[Frame, uint32] = (*Codec)(nil)
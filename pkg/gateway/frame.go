@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// lengthSize 表示帧长度字段的字节数。
+	lengthSize = 4
+	// idSize 表示消息 ID 字段的字节数。
+	idSize = 4
+)
+
+var (
+	// ErrFrameTooLarge 表示帧长度超过 MaxFrameSize。
+	ErrFrameTooLarge = errors.New("gateway: frame too large")
+
+	errShortFrame = errors.New("gateway: frame shorter than message id")
+)
+
+// Frame 表示网关收发的一帧消息。
+//
+// 线上格式为大端序的 4 字节长度、4 字节消息 ID 与负载，长度包含消息 ID 与负载：
+//
+//	+----------+----------+-----------------+
+//	| length   | id       | payload         |
+//	| uint32   | uint32   | length-4 bytes  |
+//	+----------+----------+-----------------+
+type Frame struct {
+	// ID 表示消息 ID，用于路由与解码负载。
+	ID uint32
+	// Payload 表示消息负载，通常是 proto 编码的消息。
+	Payload []byte
+}
+
+// ReadFrame 从 r 读取一帧，长度（消息 ID 与负载之和）超过 maxSize 时返回 ErrFrameTooLarge。
+func ReadFrame(r io.Reader, maxSize int) (Frame, error) {
+	var header [lengthSize + idSize]byte
+	if _, err := io.ReadFull(r, header[:lengthSize]); err != nil {
+		return Frame{}, err
+	}
+	length := binary.BigEndian.Uint32(header[:lengthSize])
+	if length < idSize {
+		return Frame{}, errShortFrame
+	}
+	if maxSize > 0 && int64(length) > int64(maxSize) {
+		return Frame{}, fmt.Errorf("%w: %d > %d", ErrFrameTooLarge, length, maxSize)
+	}
+	if _, err := io.ReadFull(r, header[lengthSize:]); err != nil {
+		return Frame{}, unexpectedEOF(err)
+	}
+	f := Frame{
+		ID:      binary.BigEndian.Uint32(header[lengthSize:]),
+		Payload: make([]byte, length-idSize),
+	}
+	if _, err := io.ReadFull(r, f.Payload); err != nil {
+		return Frame{}, unexpectedEOF(err)
+	}
+	return f, nil
+}
+
+// AppendFrame 将帧编码后追加到 b。
+func AppendFrame(b []byte, f Frame) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(idSize+len(f.Payload)))
+	b = binary.BigEndian.AppendUint32(b, f.ID)
+	return append(b, f.Payload...)
+}
+
+// WriteFrame 将帧以一次写入写到 w，长度超过 maxSize 时返回 ErrFrameTooLarge。
+// 一次写入保证 WebSocket 连接上每帧对应一条消息。
+func WriteFrame(w io.Writer, f Frame, maxSize int) error {
+	if maxSize > 0 && idSize+len(f.Payload) > maxSize {
+		return fmt.Errorf("%w: %d > %d", ErrFrameTooLarge, idSize+len(f.Payload), maxSize)
+	}
+	_, err := w.Write(AppendFrame(make([]byte, 0, lengthSize+idSize+len(f.Payload)), f))
+	return err
+}
+
+// unexpectedEOF 将帧中途的 io.EOF 转换为 io.ErrUnexpectedEOF，以区分对端正常关闭。
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
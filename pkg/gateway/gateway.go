@@ -0,0 +1,484 @@
+// Package gateway 为无法使用 gRPC 的游戏客户端提供 TCP 与 WebSocket 接入：
+// 连接上的消息按 Frame 分帧，每个连接对应一个 session.Session，并交给 router.Router 分发。
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/lk2023060901/zeus-go/pkg/app"
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/router"
+	"github.com/lk2023060901/zeus-go/pkg/service"
+	"github.com/lk2023060901/zeus-go/pkg/session"
+)
+
+// ServiceID 表示网关服务的默认 ID，同时也是其配置段名称。
+const ServiceID = "gateway"
+
+// waitPollInterval 表示等待连接全部断开时的检查间隔。
+const waitPollInterval = 10 * time.Millisecond
+
+var (
+	errNilRouter      = errors.New("gateway: router is nil")
+	errNoListener     = errors.New("gateway: neither tcp_addr nor ws_addr is set")
+	errMaxFrameSize   = errors.New("gateway: max_frame_size must be positive")
+	errMaxConns       = errors.New("gateway: max_connections must be positive")
+	errWSPath         = errors.New("gateway: ws_path must start with /")
+	errNotInitialized = errors.New("gateway: not initialized")
+)
+
+// Config 网关配置。
+type Config struct {
+	// TCPAddr 表示 TCP 监听地址，空表示不监听 TCP。
+	TCPAddr string `yaml:"tcp_addr"`
+	// WSAddr 表示 WebSocket 监听地址，空表示不监听 WebSocket。
+	WSAddr string `yaml:"ws_addr"`
+	// WSPath 表示 WebSocket 升级请求的路径，需以 / 开头。
+	WSPath string `yaml:"ws_path"`
+	// MaxFrameSize 表示单帧消息 ID 与负载的最大字节数，超过时关闭连接。
+	MaxFrameSize int `yaml:"max_frame_size"`
+	// MaxConnections 表示同时保持的最大连接数，超过时拒绝新连接。
+	MaxConnections int `yaml:"max_connections"`
+	// ReadTimeout 表示读取一帧的最长等待时间，0 表示不设置读超时，空闲连接由 Session.IdleTimeout 关闭。
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+	// WriteTimeout 表示写出一帧的最长时间，0 表示不设置写超时。
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// Session 表示每个连接的会话配置。
+	Session session.Config `yaml:"session"`
+}
+
+// DefaultConfig 返回默认配置。
+func DefaultConfig() Config {
+	return Config{
+		TCPAddr:        ":7000",
+		WSPath:         "/ws",
+		MaxFrameSize:   64 << 10,
+		MaxConnections: 10000,
+		WriteTimeout:   10 * time.Second,
+		Session:        session.DefaultConfig(),
+	}
+}
+
+// Option 网关选项。
+type Option func(*Gateway)
+
+// WithID 设置服务 ID 与配置段名称，同一应用运行多个网关时使用。
+func WithID(id string) Option {
+	return func(g *Gateway) {
+		g.id = id
+	}
+}
+
+// WithConfig 设置网关配置，配置文件中的同名配置段会在 Init 前覆盖该配置。
+func WithConfig(cfg Config) Option {
+	return func(g *Gateway) {
+		g.cfg = cfg
+	}
+}
+
+// WithRequires 设置网关依赖的组件 ID，例如处理函数使用的服务。
+func WithRequires(ids ...string) Option {
+	return func(g *Gateway) {
+		g.requires = append(g.requires, ids...)
+	}
+}
+
+// WithLogger 设置日志记录器。
+func WithLogger(l logger.Logger) Option {
+	return func(g *Gateway) {
+		g.logger = l
+	}
+}
+
+// WithSessionHooks 注册会话钩子，例如在连接断开时清理玩家状态。
+func WithSessionHooks(h session.Hooks[Frame]) Option {
+	return func(g *Gateway) {
+		g.hooks = append(g.hooks, h)
+	}
+}
+
+// Gateway 是由应用管理生命周期的客户端网关：Start 按配置监听 TCP 与 WebSocket，
+// 每个连接建立一个会话并把收到的帧交给路由器分发；会话的写协程运行在容量为
+// MaxConnections 的协程池中。Drain 停止接受新连接并等待已有连接断开，
+// Stop 关闭所有连接。
+//
+//	codec := gateway.NewCodec()
+//	_ = codec.Register(1001, (*pb.LoginReq)(nil))
+//	r := router.New[gateway.Frame, uint32](codec)
+//	_ = router.Handle(r, handleLogin)
+//	_ = application.RegisterService(gateway.New(r))
+type Gateway struct {
+	id       string
+	cfg      Config
+	requires []string
+	router   *router.Router[Frame, uint32]
+	logger   logger.Logger
+	hooks    []session.Hooks[Frame]
+
+	sessions *session.Manager[Frame]
+	pool     *conc.Pool[struct{}]
+	conns    atomic.Int64
+
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	tcp      net.Listener
+	ws       *http.Server
+	tcpAddr  net.Addr
+	wsAddr   net.Addr
+	failures chan error
+}
+
+// New 创建网关，r 负责分发连接上收到的帧。
+func New(r *router.Router[Frame, uint32], opts ...Option) *Gateway {
+	g := &Gateway{
+		id:     ServiceID,
+		cfg:    DefaultConfig(),
+		router: r,
+		logger: logger.Nop(),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// ID 返回服务 ID。
+func (g *Gateway) ID() string {
+	return g.id
+}
+
+// Requires 返回依赖的组件 ID 列表。
+func (g *Gateway) Requires() []string {
+	return g.requires
+}
+
+// Configure 从与服务 ID 同名的配置段读取配置。
+func (g *Gateway) Configure(dec app.ConfigDecoder) error {
+	return dec.Decode(&g.cfg)
+}
+
+// Init 校验配置并创建会话管理器。
+func (g *Gateway) Init(_ context.Context) error {
+	switch {
+	case g.router == nil:
+		return errNilRouter
+	case g.cfg.TCPAddr == "" && g.cfg.WSAddr == "":
+		return errNoListener
+	case g.cfg.WSAddr != "" && !strings.HasPrefix(g.cfg.WSPath, "/"):
+		return errWSPath
+	case g.cfg.MaxFrameSize <= 0:
+		return errMaxFrameSize
+	case g.cfg.MaxConnections <= 0:
+		return errMaxConns
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.sessions == nil {
+		g.newSessions()
+	}
+	return nil
+}
+
+// newSessions 创建协程池与使用该池的会话管理器，调用方需持有 g.mu。
+func (g *Gateway) newSessions() {
+	g.pool = conc.NewPool[struct{}](g.cfg.MaxConnections, conc.WithNonBlocking(true))
+	g.sessions = session.NewManager[Frame](
+		session.WithConfig(g.cfg.Session),
+		session.WithLogger(g.logger),
+		session.WithPool(g.pool),
+	)
+	for _, h := range g.hooks {
+		g.sessions.AddHooks(h)
+	}
+}
+
+// Start 按配置监听 TCP 与 WebSocket 并在后台接受连接，监听失败时返回错误。
+func (g *Gateway) Start(_ context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.sessions == nil {
+		return errNotInitialized
+	}
+	if g.ctx != nil {
+		return nil
+	}
+	if g.pool.IsClosed() {
+		// Stop 已释放协程池，重新创建会话管理器
+		g.newSessions()
+	}
+
+	var tcp, wsLis net.Listener
+	if g.cfg.TCPAddr != "" {
+		lis, err := net.Listen("tcp", g.cfg.TCPAddr)
+		if err != nil {
+			return err
+		}
+		tcp = lis
+	}
+	if g.cfg.WSAddr != "" {
+		lis, err := net.Listen("tcp", g.cfg.WSAddr)
+		if err != nil {
+			if tcp != nil {
+				_ = tcp.Close()
+			}
+			return err
+		}
+		wsLis = lis
+	}
+
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	g.failures = make(chan error, 2)
+	if tcp != nil {
+		g.tcp, g.tcpAddr = tcp, tcp.Addr()
+		failures := g.failures
+		conc.Go(func() (struct{}, error) {
+			g.acceptTCP(tcp, failures)
+			return struct{}{}, nil
+		})
+	}
+	if wsLis != nil {
+		mux := http.NewServeMux()
+		mux.Handle(g.cfg.WSPath, g.wsHandler())
+		srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		g.ws, g.wsAddr = srv, wsLis.Addr()
+		failures := g.failures
+		conc.Go(func() (struct{}, error) {
+			if err := srv.Serve(wsLis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				g.logger.Error("gateway websocket server stopped",
+					logger.Field{Key: "service", Value: g.id},
+					logger.Field{Key: "error", Value: err},
+				)
+				failures <- err
+				return struct{}{}, err
+			}
+			return struct{}{}, nil
+		})
+	}
+	g.logger.Info("gateway started",
+		logger.Field{Key: "service", Value: g.id},
+		logger.Field{Key: "tcp_addr", Value: addrString(g.tcpAddr)},
+		logger.Field{Key: "ws_addr", Value: addrString(g.wsAddr)},
+	)
+	return nil
+}
+
+// acceptTCP 持续接受 TCP 连接，直到监听关闭。
+func (g *Gateway) acceptTCP(lis net.Listener, failures chan<- error) {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			g.logger.Error("gateway tcp accept failed",
+				logger.Field{Key: "service", Value: g.id},
+				logger.Field{Key: "error", Value: err},
+			)
+			failures <- err
+			return
+		}
+		if !g.acquire() {
+			g.reject(conn.RemoteAddr().String())
+			_ = conn.Close()
+			continue
+		}
+		conc.Go(func() (struct{}, error) {
+			defer g.release()
+			g.serve(conn, conn.RemoteAddr().String())
+			return struct{}{}, nil
+		})
+	}
+}
+
+// wsHandler 返回处理 WebSocket 升级请求的 HTTP 处理器，连接数已满时返回 503。
+func (g *Gateway) wsHandler() http.Handler {
+	// 不检查 Origin：游戏客户端通常不是浏览器，需要校验时在外层代理或鉴权消息中处理
+	ws := websocket.Server{Handler: func(conn *websocket.Conn) {
+		conn.PayloadType = websocket.BinaryFrame
+		g.serve(conn, conn.Request().RemoteAddr)
+	}}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !g.acquire() {
+			g.reject(req.RemoteAddr)
+			http.Error(w, "too many connections", http.StatusServiceUnavailable)
+			return
+		}
+		defer g.release()
+		ws.ServeHTTP(w, req)
+	})
+}
+
+// acquire 占用一个连接名额，连接数已满时返回 false。
+func (g *Gateway) acquire() bool {
+	if g.conns.Add(1) > int64(g.cfg.MaxConnections) {
+		g.conns.Add(-1)
+		return false
+	}
+	return true
+}
+
+func (g *Gateway) release() {
+	g.conns.Add(-1)
+}
+
+func (g *Gateway) reject(addr string) {
+	g.logger.Warn("gateway connection rejected",
+		logger.Field{Key: "service", Value: g.id},
+		logger.Field{Key: "peer", Value: addr},
+		logger.Field{Key: "max_connections", Value: g.cfg.MaxConnections},
+	)
+}
+
+// serve 为连接建立会话并分发收到的帧，直到连接断开或会话关闭。
+func (g *Gateway) serve(conn net.Conn, addr string) {
+	g.mu.Lock()
+	ctx, sessions := g.ctx, g.sessions
+	g.mu.Unlock()
+	if ctx == nil {
+		_ = conn.Close()
+		return
+	}
+
+	s, err := sessions.Open(ctx, "", newConnTransport(conn, addr, g.cfg))
+	if err != nil {
+		_ = conn.Close()
+		g.logger.Warn("gateway session open failed",
+			logger.Field{Key: "service", Value: g.id},
+			logger.Field{Key: "peer", Value: addr},
+			logger.Field{Key: "error", Value: err},
+		)
+		return
+	}
+	if err := s.Serve(g.router.Dispatch); err != nil {
+		g.logger.Debug("gateway connection closed",
+			logger.Field{Key: "service", Value: g.id},
+			logger.Field{Key: "session", Value: s.ID()},
+			logger.Field{Key: "peer", Value: addr},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+// Drain 停止接受新连接并等待已有连接断开；ctx 结束时返回 ctx 的错误，剩余连接交由 Stop 关闭。
+func (g *Gateway) Drain(ctx context.Context) error {
+	g.mu.Lock()
+	tcp, ws := g.tcp, g.ws
+	g.mu.Unlock()
+	if tcp == nil && ws == nil {
+		return nil
+	}
+
+	g.closeListeners(ctx, tcp, ws)
+	return g.wait(ctx)
+}
+
+// Stop 停止接受新连接，关闭所有连接，等待连接处理协程退出并释放协程池；
+// 再次 Start 时会重新创建协程池与会话管理器。
+func (g *Gateway) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	cancel, tcp, ws, pool := g.cancel, g.tcp, g.ws, g.pool
+	g.ctx, g.cancel, g.tcp, g.ws, g.tcpAddr, g.wsAddr = nil, nil, nil, nil, nil, nil
+	g.mu.Unlock()
+	if cancel == nil {
+		if pool != nil {
+			pool.Release()
+		}
+		return nil
+	}
+
+	g.closeListeners(ctx, tcp, ws)
+	// 结束网关的 ctx 会关闭所有会话及其连接
+	cancel()
+	err := g.wait(ctx)
+	// 会话写协程运行在协程池中，等待其退出后释放池
+	if drainErr := pool.Drain(ctx); err == nil {
+		err = drainErr
+	}
+	pool.Release()
+	return err
+}
+
+func (g *Gateway) closeListeners(ctx context.Context, tcp net.Listener, ws *http.Server) {
+	if tcp != nil {
+		_ = tcp.Close()
+	}
+	if ws != nil {
+		// WebSocket 连接已被接管，Shutdown 只关闭监听与未升级的请求
+		_ = ws.Shutdown(ctx)
+	}
+}
+
+// wait 等待所有连接处理协程退出，ctx 结束时返回 ctx 的错误。
+func (g *Gateway) wait(ctx context.Context) error {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for g.conns.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Failures 返回运行期故障通道，监听异常退出时写入错误。
+func (g *Gateway) Failures() <-chan error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.failures
+}
+
+// Sessions 返回当前的会话管理器，用于按 ID、分组推送消息；Init 之前返回 nil。
+// Stop 后再次 Start 会替换会话管理器，调用方不应长期持有返回值。
+func (g *Gateway) Sessions() *session.Manager[Frame] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.sessions
+}
+
+// Connections 返回当前连接数。
+func (g *Gateway) Connections() int {
+	return int(g.conns.Load())
+}
+
+// TCPAddr 返回 TCP 实际监听地址，未启动或未监听时返回 nil。
+func (g *Gateway) TCPAddr() net.Addr {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.tcpAddr
+}
+
+// WSAddr 返回 WebSocket 实际监听地址，未启动或未监听时返回 nil。
+func (g *Gateway) WSAddr() net.Addr {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.wsAddr
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+var (
+	_ service.Supervised = (*Gateway)(nil)
+	_ service.Drainer    = (*Gateway)(nil)
+)
@@ -0,0 +1,228 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/lk2023060901/zeus-go/pkg/router"
+)
+
+const (
+	echoReqID  = 1001
+	echoRespID = 1002
+)
+
+func TestFrame(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteFrame(&buf, Frame{ID: 7, Payload: []byte("hello")}, 16))
+	assert.Equal(t, 4+4+5, buf.Len())
+	require.NoError(t, WriteFrame(&buf, Frame{ID: 8}, 16))
+
+	f, err := ReadFrame(&buf, 16)
+	require.NoError(t, err)
+	assert.Equal(t, Frame{ID: 7, Payload: []byte("hello")}, f)
+	f, err = ReadFrame(&buf, 16)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(8), f.ID)
+	assert.Empty(t, f.Payload)
+
+	_, err = ReadFrame(&buf, 16)
+	assert.ErrorIs(t, err, io.EOF)
+
+	assert.ErrorIs(t, WriteFrame(&buf, Frame{ID: 1, Payload: make([]byte, 13)}, 16), ErrFrameTooLarge)
+	_, err = ReadFrame(bytes.NewReader(AppendFrame(nil, Frame{ID: 1, Payload: make([]byte, 13)})), 16)
+	assert.ErrorIs(t, err, ErrFrameTooLarge)
+
+	// 帧中途断开不是正常关闭
+	truncated := AppendFrame(nil, Frame{ID: 1, Payload: []byte("abc")})
+	_, err = ReadFrame(bytes.NewReader(truncated[:len(truncated)-1]), 16)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ReadFrame(bytes.NewReader([]byte{0, 0, 0, 2, 0, 0}), 16)
+	assert.ErrorIs(t, err, errShortFrame)
+}
+
+func TestCodec(t *testing.T) {
+	c := NewCodec()
+	require.NoError(t, c.Register(echoReqID, (*wrapperspb.StringValue)(nil)))
+	assert.ErrorIs(t, c.Register(echoReqID, (*wrapperspb.Int64Value)(nil)), errMessageRegistered)
+	assert.ErrorIs(t, c.Register(9, (*wrapperspb.StringValue)(nil)), errMessageRegistered)
+
+	f, err := c.Encode(wrapperspb.String("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(echoReqID), f.ID)
+	key, err := c.Key(f)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(echoReqID), key)
+
+	msg := &wrapperspb.StringValue{}
+	require.NoError(t, c.Decode(f, msg))
+	assert.Equal(t, "hi", msg.GetValue())
+
+	_, err = c.Encode(wrapperspb.Int64(1))
+	assert.ErrorIs(t, err, errMessageUnregistered)
+}
+
+// newEchoGateway 创建回显 StringValue 的网关。
+func newEchoGateway(t *testing.T, cfg Config) *Gateway {
+	t.Helper()
+	codec := NewCodec()
+	require.NoError(t, codec.Register(echoReqID, (*wrapperspb.StringValue)(nil)))
+	require.NoError(t, codec.Register(echoRespID, (*wrapperspb.BytesValue)(nil)))
+	r := router.New[Frame, uint32](codec)
+	require.NoError(t, router.Handle(r, func(c *router.Context[Frame, uint32], msg *wrapperspb.StringValue) error {
+		return c.Reply(wrapperspb.Bytes([]byte("echo " + msg.GetValue())))
+	}))
+
+	g := New(r, WithConfig(cfg))
+	require.NoError(t, g.Init(context.Background()))
+	require.NoError(t, g.Start(context.Background()))
+	t.Cleanup(func() { _ = g.Stop(context.Background()) })
+	return g
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.TCPAddr = "127.0.0.1:0"
+	cfg.WSAddr = "127.0.0.1:0"
+	cfg.ReadTimeout = 5 * time.Second
+	return cfg
+}
+
+func payload(t *testing.T, msg proto.Message) []byte {
+	t.Helper()
+	b, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return b
+}
+
+func roundTrip(t *testing.T, conn net.Conn, text string) {
+	t.Helper()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, WriteFrame(conn, Frame{ID: echoReqID, Payload: payload(t, wrapperspb.String(text))}, 0))
+	f, err := ReadFrame(conn, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(echoRespID), f.ID)
+	resp := &wrapperspb.BytesValue{}
+	require.NoError(t, proto.Unmarshal(f.Payload, resp))
+	assert.Equal(t, "echo "+text, string(resp.GetValue()))
+}
+
+func TestGatewayTCP(t *testing.T) {
+	g := newEchoGateway(t, testConfig())
+	assert.Equal(t, ServiceID, g.ID())
+
+	conn, err := net.Dial("tcp", g.TCPAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	roundTrip(t, conn, "tcp")
+	roundTrip(t, conn, "again")
+	assert.Equal(t, 1, g.Sessions().Len())
+	assert.Equal(t, 1, g.Connections())
+
+	// 超过 MaxFrameSize 的帧会关闭连接
+	require.NoError(t, WriteFrame(conn, Frame{ID: echoReqID, Payload: make([]byte, g.cfg.MaxFrameSize)}, 0))
+	_, err = ReadFrame(conn, 0)
+	assert.Error(t, err)
+	assert.Eventually(t, func() bool { return g.Connections() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestGatewayWebSocket(t *testing.T) {
+	g := newEchoGateway(t, testConfig())
+
+	addr := g.WSAddr().String()
+	conn, err := websocket.Dial("ws://"+addr+"/ws", "", "http://"+addr+"/")
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.PayloadType = websocket.BinaryFrame
+	roundTrip(t, conn, "ws")
+	assert.Equal(t, 1, g.Sessions().Len())
+}
+
+func TestGatewayMaxConnections(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxConnections = 1
+	g := newEchoGateway(t, cfg)
+
+	first, err := net.Dial("tcp", g.TCPAddr().String())
+	require.NoError(t, err)
+	defer first.Close()
+	roundTrip(t, first, "first")
+
+	second, err := net.Dial("tcp", g.TCPAddr().String())
+	require.NoError(t, err)
+	defer second.Close()
+	require.NoError(t, second.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = ReadFrame(second, 0)
+	assert.ErrorIs(t, err, io.EOF)
+
+	addr := g.WSAddr().String()
+	_, err = websocket.Dial("ws://"+addr+"/ws", "", "http://"+addr+"/")
+	assert.Error(t, err)
+}
+
+func TestGatewayDrainAndStop(t *testing.T) {
+	g := newEchoGateway(t, testConfig())
+	tcpAddr := g.TCPAddr().String()
+
+	conn, err := net.Dial("tcp", tcpAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+	roundTrip(t, conn, "before drain")
+
+	// 排空后不再接受新连接，已有连接继续服务
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, g.Drain(ctx), context.DeadlineExceeded)
+	_, err = net.DialTimeout("tcp", tcpAddr, time.Second)
+	assert.Error(t, err)
+	roundTrip(t, conn, "draining")
+
+	require.NoError(t, g.Stop(context.Background()))
+	assert.Equal(t, 0, g.Connections())
+	assert.Nil(t, g.TCPAddr())
+	assert.True(t, g.pool.IsClosed())
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = ReadFrame(conn, 0)
+	assert.Error(t, err)
+
+	// 停止后可再次启动
+	require.NoError(t, g.Start(context.Background()))
+	assert.False(t, g.pool.IsClosed())
+	conn2, err := net.Dial("tcp", g.TCPAddr().String())
+	require.NoError(t, err)
+	defer conn2.Close()
+	roundTrip(t, conn2, "restarted")
+}
+
+func TestGatewayInit(t *testing.T) {
+	ctx := context.Background()
+	assert.ErrorIs(t, New(nil).Init(ctx), errNilRouter)
+
+	r := router.New[Frame, uint32](NewCodec())
+	cfg := DefaultConfig()
+	cfg.TCPAddr = ""
+	assert.ErrorIs(t, New(r, WithConfig(cfg)).Init(ctx), errNoListener)
+	cfg = DefaultConfig()
+	cfg.MaxFrameSize = 0
+	assert.ErrorIs(t, New(r, WithConfig(cfg)).Init(ctx), errMaxFrameSize)
+	cfg = DefaultConfig()
+	cfg.MaxConnections = 0
+	assert.ErrorIs(t, New(r, WithConfig(cfg)).Init(ctx), errMaxConns)
+	cfg = DefaultConfig()
+	cfg.WSAddr = "127.0.0.1:0"
+	cfg.WSPath = ""
+	assert.ErrorIs(t, New(r, WithConfig(cfg)).Init(ctx), errWSPath)
+	cfg.TCPAddr, cfg.WSAddr = "127.0.0.1:0", ""
+	assert.NoError(t, New(r, WithConfig(cfg)).Init(ctx))
+	assert.ErrorIs(t, New(r).Start(ctx), errNotInitialized)
+}
@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"bufio"
+	"net"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/session"
+)
+
+// connTransport 以帧为单位在 TCP 或 WebSocket 连接上收发消息。
+type connTransport struct {
+	conn         net.Conn
+	reader       *bufio.Reader
+	addr         string
+	maxFrameSize int
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func newConnTransport(conn net.Conn, addr string, cfg Config) *connTransport {
+	return &connTransport{
+		conn:         conn,
+		reader:       bufio.NewReader(conn),
+		addr:         addr,
+		maxFrameSize: cfg.MaxFrameSize,
+		readTimeout:  cfg.ReadTimeout,
+		writeTimeout: cfg.WriteTimeout,
+	}
+}
+
+// Send 在写超时内写出一帧，超时后连接不可再用，会话随之关闭。
+func (t *connTransport) Send(f Frame) error {
+	if t.writeTimeout > 0 {
+		if err := t.conn.SetWriteDeadline(time.Now().Add(t.writeTimeout)); err != nil {
+			return err
+		}
+	}
+	return WriteFrame(t.conn, f, t.maxFrameSize)
+}
+
+// Recv 在读超时内读取一帧。
+func (t *connTransport) Recv() (Frame, error) {
+	if t.readTimeout > 0 {
+		if err := t.conn.SetReadDeadline(time.Now().Add(t.readTimeout)); err != nil {
+			return Frame{}, err
+		}
+	}
+	return ReadFrame(t.reader, t.maxFrameSize)
+}
+
+func (t *connTransport) Close() error {
+	return t.conn.Close()
+}
+
+func (t *connTransport) RemoteAddr() string {
+	return t.addr
+}
+
+var _ session.Transport[Frame] = (*connTransport)(nil)
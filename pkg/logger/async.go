@@ -0,0 +1,284 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy 表示异步缓冲区写满时的处理策略。
+type OverflowPolicy string
+
+const (
+	// OverflowBlock 表示阻塞调用方直到缓冲区有空位，不丢弃日志。
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest 表示丢弃当前写入的日志。
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropDebug 表示优先丢弃缓冲区中最早的 Debug 日志，没有 Debug 日志时丢弃当前写入的日志。
+	OverflowDropDebug OverflowPolicy = "drop_debug"
+)
+
+const (
+	defaultAsyncBufferSize    = 4096
+	defaultAsyncFlushInterval = time.Second
+)
+
+var errInvalidOverflow = errors.New("logger: invalid overflow policy")
+
+// AsyncConfig 定义异步写入配置：日志在调用方编码后进入内存缓冲区，由后台 goroutine 定期或在缓冲区过半时批量写出。
+type AsyncConfig struct {
+	// Enabled 表示是否启用异步写入。
+	Enabled bool `yaml:"enabled"`
+	// BufferSize 表示每个输出目标缓冲的日志条数，默认 4096。
+	BufferSize int `yaml:"buffer_size"`
+	// FlushInterval 表示后台写出的间隔，默认 1 秒。
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	// Overflow 表示缓冲区写满时的处理策略，默认 OverflowBlock。
+	Overflow OverflowPolicy `yaml:"overflow"`
+}
+
+func (c AsyncConfig) withDefaults() (AsyncConfig, error) {
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultAsyncBufferSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaultAsyncFlushInterval
+	}
+	switch c.Overflow {
+	case "":
+		c.Overflow = OverflowBlock
+	case OverflowBlock, OverflowDropNewest, OverflowDropDebug:
+	default:
+		return c, fmt.Errorf("%w: %q", errInvalidOverflow, c.Overflow)
+	}
+	return c, nil
+}
+
+type asyncEntry struct {
+	level zapcore.Level
+	buf   *buffer.Buffer
+}
+
+// asyncWriter 缓冲已编码的日志并在后台写到 ws。
+type asyncWriter struct {
+	ws       zapcore.WriteSyncer
+	size     int
+	interval time.Duration
+	policy   OverflowPolicy
+
+	// writeMu 串行化写出，先于 mu 获取，保证日志按写入顺序落盘
+	writeMu sync.Mutex
+	spare   []asyncEntry
+
+	mu     sync.Mutex
+	space  *sync.Cond
+	queue  []asyncEntry
+	debug  int
+	closed bool
+
+	kick    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	dropped atomic.Uint64
+}
+
+func newAsyncWriter(ws zapcore.WriteSyncer, cfg AsyncConfig) *asyncWriter {
+	w := &asyncWriter{
+		ws:       ws,
+		size:     cfg.BufferSize,
+		interval: cfg.FlushInterval,
+		policy:   cfg.Overflow,
+		queue:    make([]asyncEntry, 0, cfg.BufferSize),
+		spare:    make([]asyncEntry, 0, cfg.BufferSize),
+		kick:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	w.space = sync.NewCond(&w.mu)
+	return w
+}
+
+// start 启动后台写出 goroutine。
+func (w *asyncWriter) start() {
+	go w.run()
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.kick:
+		case <-w.done:
+			w.report(w.flush())
+			return
+		}
+		w.report(w.flush())
+	}
+}
+
+// write 接管 buf，缓冲区满时按策略处理；关闭后直接同步写出。
+func (w *asyncWriter) write(level zapcore.Level, buf *buffer.Buffer) {
+	w.mu.Lock()
+	for !w.closed && len(w.queue) >= w.size {
+		if w.policy == OverflowBlock {
+			w.flushSoon()
+			w.space.Wait()
+			continue
+		}
+		if w.policy == OverflowDropDebug && level > zapcore.DebugLevel && w.evictDebug() {
+			break
+		}
+		w.mu.Unlock()
+		w.dropped.Add(1)
+		buf.Free()
+		return
+	}
+	if w.closed {
+		w.mu.Unlock()
+		w.writeMu.Lock()
+		err := w.flushLocked()
+		if _, werr := w.ws.Write(buf.Bytes()); err == nil {
+			err = werr
+		}
+		w.writeMu.Unlock()
+		buf.Free()
+		w.report(err)
+		return
+	}
+	w.queue = append(w.queue, asyncEntry{level: level, buf: buf})
+	if level == zapcore.DebugLevel {
+		w.debug++
+	}
+	if len(w.queue) >= w.size/2 {
+		w.flushSoon()
+	}
+	w.mu.Unlock()
+}
+
+// evictDebug 丢弃缓冲区中最早的 Debug 日志，调用方需持有 mu。
+func (w *asyncWriter) evictDebug() bool {
+	if w.debug == 0 {
+		return false
+	}
+	i := slices.IndexFunc(w.queue, func(e asyncEntry) bool {
+		return e.level == zapcore.DebugLevel
+	})
+	w.queue[i].buf.Free()
+	w.queue = slices.Delete(w.queue, i, i+1)
+	w.debug--
+	w.dropped.Add(1)
+	return true
+}
+
+// flushSoon 通知后台 goroutine 尽快写出。
+func (w *asyncWriter) flushSoon() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// flush 写出缓冲区中的全部日志。
+func (w *asyncWriter) flush() error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return w.flushLocked()
+}
+
+// flushLocked 写出缓冲区中的全部日志，调用方需持有 writeMu。
+func (w *asyncWriter) flushLocked() error {
+	w.mu.Lock()
+	batch := w.queue
+	w.queue = w.spare
+	w.debug = 0
+	w.space.Broadcast()
+	w.mu.Unlock()
+
+	var err error
+	for _, e := range batch {
+		if _, werr := w.ws.Write(e.buf.Bytes()); werr != nil && err == nil {
+			err = werr
+		}
+		e.buf.Free()
+	}
+	clear(batch)
+	w.spare = batch[:0]
+	return err
+}
+
+// Sync 写出缓冲区中的全部日志并同步底层输出。
+func (w *asyncWriter) Sync() error {
+	return errors.Join(w.flush(), w.ws.Sync())
+}
+
+// Close 停止后台 goroutine 并写出剩余日志，之后的日志直接同步写出。
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.space.Broadcast()
+	w.mu.Unlock()
+
+	close(w.done)
+	<-w.stopped
+	return w.Sync()
+}
+
+// report 将后台写出失败输出到标准错误，与 zap 内部错误的处理方式一致。
+func (w *asyncWriter) report(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v logger: async write error: %v\n", time.Now(), err)
+	}
+}
+
+// asyncCore 在调用方编码日志，将写出交给 asyncWriter。
+type asyncCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out *asyncWriter
+}
+
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &asyncCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out}
+}
+
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.out.write(ent.Level, buf)
+	if ent.Level > zapcore.ErrorLevel {
+		// 与 zapcore.NewCore 一致，panic 与 fatal 日志在进程退出前落盘
+		return c.out.Sync()
+	}
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	return c.out.Sync()
+}
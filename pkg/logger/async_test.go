@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// syncBuffer 是并发安全的 WriteSyncer，记录写出的内容。
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Sync() error {
+	return nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+var testBuffers = buffer.NewPool()
+
+func line(s string) *buffer.Buffer {
+	buf := testBuffers.Get()
+	buf.AppendString(s + "\n")
+	return buf
+}
+
+func newTestAsyncWriter(t *testing.T, ws zapcore.WriteSyncer, cfg AsyncConfig) *asyncWriter {
+	t.Helper()
+	cfg.FlushInterval = time.Hour
+	cfg, err := cfg.withDefaults()
+	require.NoError(t, err)
+	return newAsyncWriter(ws, cfg)
+}
+
+func TestAsyncWriterDropDebug(t *testing.T) {
+	out := &syncBuffer{}
+	// 未启动后台 goroutine，缓冲区只在 flush 时写出
+	w := newTestAsyncWriter(t, out, AsyncConfig{BufferSize: 3, Overflow: OverflowDropDebug})
+
+	w.write(zapcore.DebugLevel, line("d1"))
+	w.write(zapcore.InfoLevel, line("i1"))
+	w.write(zapcore.DebugLevel, line("d2"))
+	w.write(zapcore.InfoLevel, line("i2"))
+	w.write(zapcore.DebugLevel, line("d3"))
+	w.write(zapcore.WarnLevel, line("w1"))
+	w.write(zapcore.ErrorLevel, line("e1"))
+	assert.Equal(t, uint64(4), w.dropped.Load())
+	assert.Empty(t, out.String())
+
+	require.NoError(t, w.flush())
+	assert.Equal(t, "i1\ni2\nw1\n", out.String())
+}
+
+func TestAsyncWriterDropNewest(t *testing.T) {
+	out := &syncBuffer{}
+	w := newTestAsyncWriter(t, out, AsyncConfig{BufferSize: 2, Overflow: OverflowDropNewest})
+
+	w.write(zapcore.DebugLevel, line("a"))
+	w.write(zapcore.ErrorLevel, line("b"))
+	w.write(zapcore.ErrorLevel, line("c"))
+	assert.Equal(t, uint64(1), w.dropped.Load())
+	require.NoError(t, w.Sync())
+	assert.Equal(t, "a\nb\n", out.String())
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	out := &syncBuffer{}
+	w := newTestAsyncWriter(t, out, AsyncConfig{BufferSize: 1})
+	w.write(zapcore.InfoLevel, line("a"))
+
+	written := make(chan struct{})
+	go func() {
+		w.write(zapcore.InfoLevel, line("b"))
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("write returned while buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// 缓冲区写出后阻塞的写入继续
+	require.NoError(t, w.flush())
+	<-written
+	require.NoError(t, w.flush())
+	assert.Equal(t, "a\nb\n", out.String())
+	assert.Zero(t, w.dropped.Load())
+}
+
+func TestAsyncWriterBackground(t *testing.T) {
+	out := &syncBuffer{}
+	w := newAsyncWriter(out, AsyncConfig{BufferSize: 100, FlushInterval: 5 * time.Millisecond, Overflow: OverflowBlock})
+	w.start()
+
+	w.write(zapcore.InfoLevel, line("tick"))
+	assert.Eventually(t, func() bool { return out.String() == "tick\n" }, time.Second, time.Millisecond)
+
+	w.write(zapcore.InfoLevel, line("last"))
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	assert.Equal(t, "tick\nlast\n", out.String())
+
+	// 关闭后同步写出
+	w.write(zapcore.InfoLevel, line("after"))
+	assert.Equal(t, "tick\nlast\nafter\n", out.String())
+}
+
+func TestZapLoggerAsync(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "async.log")
+	errPath := filepath.Join(dir, "error.log")
+	l, err := NewZapLogger(ZapConfig{
+		Filepath: logPath,
+		Level:    LevelDebug,
+		Outputs: []ZapOutput{
+			{Type: OutputFile},
+			{Type: OutputFile, Filepath: errPath, Level: LevelError},
+		},
+		Async: AsyncConfig{Enabled: true, FlushInterval: time.Hour, Overflow: OverflowDropDebug},
+	})
+	require.NoError(t, err)
+	require.Len(t, l.async, 2)
+
+	child := l.With(Field{Key: "k", Value: "v"})
+	for i := range 10 {
+		child.Info("tick", Field{Key: "i", Value: i})
+	}
+	l.Error("boom")
+	require.NoError(t, child.Sync())
+
+	records := readLogRecords(t, logPath)
+	require.Len(t, records, 11)
+	for i, record := range records[:10] {
+		assert.Equal(t, float64(i), record["i"])
+		assert.Equal(t, "v", record["k"])
+	}
+	assert.Len(t, readLogRecords(t, errPath), 1)
+	assert.Zero(t, l.Dropped())
+	require.NoError(t, l.Close())
+
+	_, err = NewZapLogger(ZapConfig{Filepath: logPath, Async: AsyncConfig{Enabled: true, Overflow: "drop_oldest"}})
+	assert.ErrorIs(t, err, errInvalidOverflow)
+}
+
+func TestAsyncConfigYAML(t *testing.T) {
+	var cfg NamedConfig
+	raw := "name: tick\nasync:\n  enabled: true\n  buffer_size: 1024\n  flush_interval: 200ms\n  overflow: drop_debug\n"
+	require.NoError(t, yaml.NewDecoder(strings.NewReader(raw)).Decode(&cfg))
+	assert.Equal(t, AsyncConfig{
+		Enabled:       true,
+		BufferSize:    1024,
+		FlushInterval: 200 * time.Millisecond,
+		Overflow:      OverflowDropDebug,
+	}, cfg.Async)
+}
@@ -10,6 +10,9 @@ import (
 // Config 表示日志配置结构。
 type Config struct {
 	Loggers []NamedConfig `yaml:"loggers"`
+	// LoggerLevels 表示按名称模式覆盖的日志等级，在全部具名日志注册后依次应用。
+	// 配置键与应用配置中的同名字段一致。
+	LoggerLevels []LevelOverride `yaml:"logger_levels"`
 }
 
 // NamedConfig 表示单个具名日志配置。
@@ -22,6 +25,24 @@ type NamedConfig struct {
 	MaxAge     int    `yaml:"max_age"`
 	Compress   bool   `yaml:"compress"`
 	EnableEnv  string `yaml:"enable_env"`
+	// Outputs 表示输出列表，为空时以 JSON 写入 Filepath。
+	Outputs []OutputConfig `yaml:"outputs"`
+	// Async 表示异步写入配置。
+	Async AsyncConfig `yaml:"async"`
+}
+
+// OutputConfig 表示具名日志的单个输出配置。
+type OutputConfig struct {
+	// Type 表示输出目标（file、stdout、stderr），为空时视为 file。
+	Type string `yaml:"type"`
+	// Encoding 表示编码格式（json、console），为空时视为 json。
+	Encoding string `yaml:"encoding"`
+	// Level 表示该输出的最低等级，为空时不额外限制。
+	Level string `yaml:"level"`
+	// Filepath 表示文件输出的路径，为空时使用所属日志的 Filepath。
+	Filepath string `yaml:"filepath"`
+	// Color 表示 console 编码是否按等级着色。
+	Color bool `yaml:"color"`
 }
 
 // InitFromConfig 根据配置创建并注册具名日志实例。
@@ -44,18 +65,20 @@ func InitFromConfig(cfg Config) error {
 			return err
 		}
 
-		path := resolveFilepath(item.Filepath)
-		if path == "" {
-			return errEmptyLogPath
+		outputs, err := toZapOutputs(item.Outputs)
+		if err != nil {
+			return err
 		}
 
 		l, err := NewZapLogger(ZapConfig{
-			Filepath:   path,
+			Filepath:   resolveFilepath(item.Filepath),
+			Outputs:    outputs,
 			Level:      level,
 			MaxSize:    item.MaxSize,
 			MaxBackups: item.MaxBackups,
 			MaxAge:     item.MaxAge,
 			Compress:   item.Compress,
+			Async:      item.Async,
 		})
 		if err != nil {
 			return err
@@ -65,7 +88,32 @@ func InitFromConfig(cfg Config) error {
 			return err
 		}
 	}
-	return nil
+	return ApplyLevelOverrides(cfg.LoggerLevels)
+}
+
+func toZapOutputs(items []OutputConfig) ([]ZapOutput, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	outputs := make([]ZapOutput, 0, len(items))
+	for _, item := range items {
+		level := LevelDebug
+		if strings.TrimSpace(item.Level) != "" {
+			parsed, err := parseLevel(item.Level)
+			if err != nil {
+				return nil, err
+			}
+			level = parsed
+		}
+		outputs = append(outputs, ZapOutput{
+			Type:     OutputType(strings.ToLower(strings.TrimSpace(item.Type))),
+			Encoding: Encoding(strings.ToLower(strings.TrimSpace(item.Encoding))),
+			Level:    level,
+			Filepath: resolveFilepath(item.Filepath),
+			Color:    item.Color,
+		})
+	}
+	return outputs, nil
 }
 
 func envEnabled(key string) bool {
@@ -84,6 +132,11 @@ func envEnabled(key string) bool {
 	}
 }
 
+// ParseLevel 解析日志等级名称（debug、info、warn、error），空字符串视为 info。
+func ParseLevel(raw string) (Level, error) {
+	return parseLevel(raw)
+}
+
 func parseLevel(raw string) (Level, error) {
 	switch strings.ToLower(strings.TrimSpace(raw)) {
 	case "":
@@ -122,3 +122,53 @@ func TestInitFromConfigEnabled(t *testing.T) {
 	_, ok := l.(*ZapLogger)
 	assert.True(t, ok)
 }
+
+func TestInitFromConfigOutputs(t *testing.T) {
+	resetRegistry()
+
+	dir := t.TempDir()
+	errPath := filepath.Join(dir, "error.log")
+	cfg := Config{
+		Loggers: []NamedConfig{
+			{
+				Name:     "ws",
+				Filepath: filepath.Join(dir, "ws.log"),
+				Level:    "debug",
+				Outputs: []OutputConfig{
+					{Type: "file"},
+					{Type: "File", Filepath: errPath, Level: "error"},
+				},
+			},
+		},
+	}
+	require.NoError(t, InitFromConfig(cfg))
+
+	l := Get("ws")
+	l.Info("info_msg")
+	l.Error("error_msg")
+	require.NoError(t, l.Sync())
+
+	assert.Len(t, readLogRecords(t, filepath.Join(dir, "ws.log")), 2)
+	records := readLogRecords(t, errPath)
+	require.Len(t, records, 1)
+	assert.Equal(t, "error_msg", records[0]["msg"])
+}
+
+func TestInitFromConfigInvalidOutput(t *testing.T) {
+	resetRegistry()
+
+	cfg := Config{
+		Loggers: []NamedConfig{
+			{
+				Name:    "ws",
+				Outputs: []OutputConfig{{Type: "stdout", Level: "verbose"}},
+			},
+		},
+	}
+	assert.Error(t, InitFromConfig(cfg))
+
+	cfg.Loggers[0].Outputs = []OutputConfig{{Type: "stdout", Encoding: "console"}}
+	require.NoError(t, InitFromConfig(cfg))
+	_, ok := Get("ws").(*ZapLogger)
+	assert.True(t, ok)
+}
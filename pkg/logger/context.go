@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	// RequestIDKey 表示请求 ID 的日志字段名。
+	RequestIDKey = "request_id"
+	// TraceIDKey 表示链路追踪 ID 的日志字段名。
+	TraceIDKey = "trace_id"
+)
+
+type contextFieldsKey struct{}
+
+type requestIDKey struct{}
+
+type traceIDKey struct{}
+
+// WithContextFields 返回附加日志字段的 ctx，由其派生的 ctx 传给 *Context 方法时会自动输出这些字段。
+// 多次调用时字段按调用顺序累加。
+func WithContextFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	prev, _ := ctx.Value(contextFieldsKey{}).([]Field)
+	return context.WithValue(ctx, contextFieldsKey{}, slices.Concat(prev, fields))
+}
+
+// WithRequestID 返回携带请求 ID 的 ctx，日志中输出为 request_id 字段。
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求 ID。
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithTraceID 返回携带链路追踪 ID 的 ctx，日志中输出为 trace_id 字段。
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceIDFromContext 返回 ctx 中的链路追踪 ID。
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(traceIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ContextFields 返回 ctx 携带的日志字段：依次为请求 ID、链路追踪 ID 与 WithContextFields 附加的字段。
+// Logger 实现应在 Log 中输出这些字段，且不受 WithGroup 分组影响。
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	extra, _ := ctx.Value(contextFieldsKey{}).([]Field)
+	requestID, hasRequest := RequestIDFromContext(ctx)
+	traceID, hasTrace := TraceIDFromContext(ctx)
+	if !hasRequest && !hasTrace {
+		return extra
+	}
+
+	fields := make([]Field, 0, len(extra)+2)
+	if hasRequest {
+		fields = append(fields, Field{Key: RequestIDKey, Value: requestID})
+	}
+	if hasTrace {
+		fields = append(fields, Field{Key: TraceIDKey, Value: traceID})
+	}
+	return append(fields, extra...)
+}
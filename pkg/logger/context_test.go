@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextFields(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, ContextFields(ctx))
+	assert.Equal(t, ctx, WithContextFields(ctx))
+
+	ctx = WithContextFields(ctx, Field{Key: "player", Value: 42})
+	child := WithContextFields(ctx, Field{Key: "job", Value: "reset"})
+	assert.Equal(t, []Field{{Key: "player", Value: 42}}, ContextFields(ctx))
+	assert.Equal(t, []Field{{Key: "player", Value: 42}, {Key: "job", Value: "reset"}}, ContextFields(child))
+
+	child = WithTraceID(WithRequestID(child, "req-1"), "trace-1")
+	assert.Equal(t, []Field{
+		{Key: RequestIDKey, Value: "req-1"},
+		{Key: TraceIDKey, Value: "trace-1"},
+		{Key: "player", Value: 42},
+		{Key: "job", Value: "reset"},
+	}, ContextFields(child))
+
+	id, ok := RequestIDFromContext(WithRequestID(context.Background(), ""))
+	assert.False(t, ok)
+	assert.Empty(t, id)
+}
+
+func TestZapLoggerContextFields(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "ctx.log")
+	l, err := NewZapLogger(ZapConfig{Filepath: logPath, Level: LevelDebug})
+	require.NoError(t, err)
+
+	ctx := WithContextFields(WithTraceID(context.Background(), "trace-1"), Field{Key: "player", Value: "p1"})
+	l.WithGroup("ws").InfoContext(ctx, "login", Field{Key: "id", Value: 1})
+	l.Info("no_ctx")
+	require.NoError(t, l.Sync())
+
+	records := readLogRecords(t, logPath)
+	assert.True(t, hasRecord(records, "login", TraceIDKey, "trace-1"))
+	assert.True(t, hasRecord(records, "login", "player", "p1"))
+	assert.True(t, hasRecord(records, "login", "ws.id", float64(1)))
+	assert.False(t, hasRecord(records, "no_ctx", TraceIDKey, "trace-1"))
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrLoggerNotFound 表示指定名称的 Logger 未注册。
+	ErrLoggerNotFound = errors.New("logger: logger not registered")
+	// ErrLevelUnsupported 表示 Logger 未实现 LevelController，例如 Nop。
+	ErrLevelUnsupported = errors.New("logger: logger does not support level changes")
+)
+
+// LevelController 定义支持运行时调整等级的 Logger，*ZapLogger 实现了该接口。
+type LevelController interface {
+	// Level 返回当前日志输出等级。
+	Level() Level
+	// SetLevel 在运行时调整日志输出等级。
+	SetLevel(level Level)
+}
+
+// LevelOverride 表示按名称模式覆盖日志等级的配置。
+type LevelOverride struct {
+	// Pattern 表示 path.Match 语法的名称模式，例如 "rpc.*"。
+	Pattern string `yaml:"pattern"`
+	// Level 表示匹配的 Logger 使用的等级。
+	Level string `yaml:"level"`
+}
+
+type levelPattern struct {
+	pattern string
+	level   Level
+}
+
+// levelPatterns 按设置顺序保存名称模式，后设置的优先，受 registryMu 保护。
+var levelPatterns []levelPattern
+
+// String 返回等级名称，与 ParseLevel 互逆。
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// SetLevel 调整已注册 Logger 的等级，对由其派生的 Logger 同时生效。
+func SetLevel(name string, level Level) error {
+	ctl, err := levelController(name)
+	if err != nil {
+		return err
+	}
+	ctl.SetLevel(level)
+	return nil
+}
+
+// GetLevel 返回已注册 Logger 的当前等级。
+func GetLevel(name string) (Level, error) {
+	ctl, err := levelController(name)
+	if err != nil {
+		return LevelInfo, err
+	}
+	return ctl.Level(), nil
+}
+
+// SetLevels 将等级应用到名称匹配 pattern 的已注册 Logger，返回被调整的名称。
+// 模式会被保留，之后注册的匹配 Logger 同样使用该等级；不支持调整等级的 Logger 会被跳过。
+func SetLevels(pattern string, level Level) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("logger: invalid pattern %q: %w", pattern, err)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	levelPatterns = slices.DeleteFunc(levelPatterns, func(p levelPattern) bool {
+		return p.pattern == pattern
+	})
+	levelPatterns = append(levelPatterns, levelPattern{pattern: pattern, level: level})
+
+	var names []string
+	for name, l := range registryByName {
+		ctl, ok := l.(LevelController)
+		if !ok {
+			continue
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			ctl.SetLevel(level)
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// ResetLevelPatterns 清除 SetLevels 保留的名称模式，已调整的等级保持不变。
+func ResetLevelPatterns() {
+	registryMu.Lock()
+	levelPatterns = nil
+	registryMu.Unlock()
+}
+
+// ApplyLevelOverrides 依次应用等级覆盖配置，遇到非法等级或模式时返回错误并停止。
+func ApplyLevelOverrides(overrides []LevelOverride) error {
+	for _, item := range overrides {
+		level, err := parseLevel(item.Level)
+		if err != nil {
+			return err
+		}
+		if _, err := SetLevels(strings.TrimSpace(item.Pattern), level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyLevelPatterns 将最后一个匹配的名称模式应用到新注册的 Logger，调用方需持有 registryMu。
+func applyLevelPatterns(name string, l Logger) {
+	ctl, ok := l.(LevelController)
+	if !ok {
+		return
+	}
+	for i := len(levelPatterns) - 1; i >= 0; i-- {
+		if matched, _ := path.Match(levelPatterns[i].pattern, name); matched {
+			ctl.SetLevel(levelPatterns[i].level)
+			return
+		}
+	}
+}
+
+func levelController(name string) (LevelController, error) {
+	registryMu.RLock()
+	l, ok := registryByName[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrLoggerNotFound, name)
+	}
+	ctl, ok := l.(LevelController)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrLevelUnsupported, name)
+	}
+	return ctl, nil
+}
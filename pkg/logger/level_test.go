@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func newTestZapLogger(t *testing.T, level Level) *ZapLogger {
+	t.Helper()
+	l, err := NewZapLogger(ZapConfig{
+		Filepath: filepath.Join(t.TempDir(), "level.log"),
+		Level:    level,
+	})
+	require.NoError(t, err)
+	return l
+}
+
+func TestSetLevel(t *testing.T) {
+	resetRegistry()
+
+	l := newTestZapLogger(t, LevelInfo)
+	derived := l.With(Field{Key: "k", Value: "v"}).WithGroup("g")
+	require.NoError(t, Register("conc", l))
+	require.NoError(t, Register("nop", Nop()))
+	assert.False(t, derived.Enabled(context.Background(), LevelDebug))
+
+	require.NoError(t, SetLevel("conc", LevelDebug))
+	assert.True(t, derived.Enabled(context.Background(), LevelDebug))
+	level, err := GetLevel("conc")
+	require.NoError(t, err)
+	assert.Equal(t, LevelDebug, level)
+
+	assert.ErrorIs(t, SetLevel("missing", LevelDebug), ErrLoggerNotFound)
+	assert.ErrorIs(t, SetLevel("nop", LevelDebug), ErrLevelUnsupported)
+	_, err = GetLevel("nop")
+	assert.ErrorIs(t, err, ErrLevelUnsupported)
+}
+
+func TestSetLevels(t *testing.T) {
+	resetRegistry()
+
+	rpc := newTestZapLogger(t, LevelInfo)
+	gw := newTestZapLogger(t, LevelInfo)
+	require.NoError(t, Register("rpc.server", rpc))
+	require.NoError(t, Register("gateway", gw))
+	require.NoError(t, Register("rpc.nop", Nop()))
+
+	names, err := SetLevels("rpc.*", LevelDebug)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rpc.server"}, names)
+	assert.Equal(t, LevelDebug, rpc.Level())
+	assert.Equal(t, LevelInfo, gw.Level())
+
+	// 之后注册的匹配 Logger 同样生效，后设置的模式优先
+	_, err = SetLevels("rpc.client", LevelError)
+	require.NoError(t, err)
+	client := newTestZapLogger(t, LevelInfo)
+	require.NoError(t, Register("rpc.client", client))
+	assert.Equal(t, LevelError, client.Level())
+	other := newTestZapLogger(t, LevelInfo)
+	require.NoError(t, Register("rpc.other", other))
+	assert.Equal(t, LevelDebug, other.Level())
+
+	ResetLevelPatterns()
+	late := newTestZapLogger(t, LevelInfo)
+	require.NoError(t, Register("rpc.late", late))
+	assert.Equal(t, LevelInfo, late.Level())
+
+	_, err = SetLevels("[", LevelDebug)
+	assert.Error(t, err)
+}
+
+func TestInitFromConfigLevels(t *testing.T) {
+	resetRegistry()
+
+	dir := t.TempDir()
+	cfg := Config{
+		Loggers: []NamedConfig{
+			{Name: "rpc.server", Filepath: filepath.Join(dir, "rpc.log"), Level: "info"},
+			{Name: "gateway", Filepath: filepath.Join(dir, "gateway.log"), Level: "info"},
+		},
+		LoggerLevels: []LevelOverride{{Pattern: "rpc.*", Level: "warn"}},
+	}
+	require.NoError(t, InitFromConfig(cfg))
+
+	level, err := GetLevel("rpc.server")
+	require.NoError(t, err)
+	assert.Equal(t, LevelWarn, level)
+	level, err = GetLevel("gateway")
+	require.NoError(t, err)
+	assert.Equal(t, LevelInfo, level)
+
+	assert.Error(t, ApplyLevelOverrides([]LevelOverride{{Pattern: "*", Level: "verbose"}}))
+}
+
+func TestConfigLevelsYAML(t *testing.T) {
+	var cfg Config
+	raw := "loggers: [{name: rpc.server}]\nlogger_levels: [{pattern: 'rpc.*', level: warn}]\n"
+	require.NoError(t, yaml.NewDecoder(strings.NewReader(raw)).Decode(&cfg))
+	assert.Equal(t, []LevelOverride{{Pattern: "rpc.*", Level: "warn"}}, cfg.LoggerLevels)
+}
+
+func TestLevelString(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		parsed, err := ParseLevel(level.String())
+		require.NoError(t, err)
+		assert.Equal(t, level, parsed)
+	}
+	assert.Equal(t, "level(9)", Level(9).String())
+}
@@ -16,7 +16,7 @@ var (
 	registryByName = make(map[string]Logger)
 )
 
-// Register 注册具名 Logger。
+// Register 注册具名 Logger，名称匹配 SetLevels 保留的模式时同时调整其等级。
 func Register(name string, l Logger) error {
 	if name == "" {
 		return errEmptyLoggerName
@@ -30,6 +30,7 @@ func Register(name string, l Logger) error {
 		return errLoggerRegistered
 	}
 	registryByName[name] = l
+	applyLevelPatterns(name, l)
 	return nil
 }
 
@@ -54,3 +55,36 @@ func Names() []string {
 	}
 	return names
 }
+
+// SyncAll 刷新全部已注册 Logger 的缓冲，返回合并后的错误。
+func SyncAll() error {
+	var errs []error
+	for _, l := range registered() {
+		errs = append(errs, l.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// CloseAll 写出全部已注册 Logger 的缓冲并停止异步写入的后台 goroutine，之后的日志同步写出。
+// 未实现 Close 的 Logger 仅执行 Sync。
+func CloseAll() error {
+	var errs []error
+	for _, l := range registered() {
+		if c, ok := l.(interface{ Close() error }); ok {
+			errs = append(errs, c.Close())
+			continue
+		}
+		errs = append(errs, l.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+func registered() []Logger {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	loggers := make([]Logger, 0, len(registryByName))
+	for _, l := range registryByName {
+		loggers = append(loggers, l)
+	}
+	return loggers
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -10,6 +12,7 @@ import (
 func resetRegistry() {
 	registryMu.Lock()
 	registryByName = make(map[string]Logger)
+	levelPatterns = nil
 	registryMu.Unlock()
 }
 
@@ -47,3 +50,25 @@ func TestNames(t *testing.T) {
 	assert.Contains(t, names, "a")
 	assert.Contains(t, names, "b")
 }
+
+func TestSyncAllAndCloseAll(t *testing.T) {
+	resetRegistry()
+
+	logPath := filepath.Join(t.TempDir(), "registry.log")
+	l, err := NewZapLogger(ZapConfig{
+		Filepath: logPath,
+		Level:    LevelInfo,
+		Async:    AsyncConfig{Enabled: true, FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+	require.NoError(t, Register("async", l))
+	require.NoError(t, Register("nop", Nop()))
+
+	l.Info("first")
+	require.NoError(t, SyncAll())
+	assert.Len(t, readLogRecords(t, logPath), 1)
+
+	l.Info("second")
+	require.NoError(t, CloseAll())
+	assert.Len(t, readLogRecords(t, logPath), 2)
+}
@@ -0,0 +1,249 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// NewSlogHandler 返回由 l 输出的 slog.Handler，便于将使用 slog 的第三方库接入具名日志：
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(logger.Get("thirdparty"))))
+//
+// 分组与 Logger.WithGroup 一致，以 "." 连接为字段名前缀。*ZapLogger 与 FromSlogHandler 返回的 Logger
+// 沿用记录的时间与调用位置，其他 Logger 以 source 字段（file:line）输出调用位置。
+func NewSlogHandler(l Logger) slog.Handler {
+	if l == nil {
+		l = Nop()
+	}
+	return &slogHandler{l: l}
+}
+
+type slogHandler struct {
+	l Logger
+}
+
+// sourceLogger 定义能以给定时间与调用位置输出日志的 Logger，pc 为 0 表示调用位置未知。
+type sourceLogger interface {
+	logRecord(ctx context.Context, level Level, t time.Time, pc uintptr, msg string, fields []Field)
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.l.Enabled(ctx, fromSlogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttrFields(fields, "", a)
+		return true
+	})
+	level := fromSlogLevel(r.Level)
+	if sl, ok := h.l.(sourceLogger); ok {
+		sl.logRecord(ctx, level, r.Time, r.PC, r.Message, fields)
+		return nil
+	}
+	if frame, ok := sourceFrame(r.PC); ok {
+		fields = append(fields, Field{Key: "source", Value: frame.File + ":" + strconv.Itoa(frame.Line)})
+	}
+	h.l.Log(ctx, level, r.Message, fields...)
+	return nil
+}
+
+// sourceFrame 返回 pc 对应的调用位置。
+func sourceFrame(pc uintptr) (runtime.Frame, bool) {
+	if pc == 0 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame, frame.File != ""
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendAttrFields(fields, "", a)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	return &slogHandler{l: h.l.With(fields...)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l.WithGroup(name)}
+}
+
+// appendAttrFields 将 slog 属性展开为字段：忽略空属性与空分组，分组以 "." 连接为前缀，空键分组直接内联。
+func appendAttrFields(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := prefix
+		if a.Key != "" {
+			group = joinKey(prefix, a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttrFields(fields, group, ga)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: joinKey(prefix, a.Key), Value: a.Value.Any()})
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// FromSlogHandler 返回由 h 输出的 Logger。With 与 WithGroup 映射到 h 的 WithAttrs 与 WithGroup，
+// ctx 携带的字段（见 ContextFields）作为记录属性输出，受 slog 语义限制会位于当前分组内。
+func FromSlogHandler(h slog.Handler) Logger {
+	if h == nil {
+		return Nop()
+	}
+	return &slogLogger{h: h}
+}
+
+type slogLogger struct {
+	h slog.Handler
+}
+
+func (l *slogLogger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	return &slogLogger{h: l.h.WithAttrs(toAttrs(nil, fields))}
+}
+
+func (l *slogLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	return &slogLogger{h: l.h.WithGroup(name)}
+}
+
+func (l *slogLogger) Enabled(ctx context.Context, level Level) bool {
+	return l.h.Enabled(ctx, toSlogLevel(level))
+}
+
+func (l *slogLogger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	l.log(ctx, level, msg, fields)
+}
+
+func (l *slogLogger) Debug(msg string, fields ...Field) {
+	l.log(context.Background(), LevelDebug, msg, fields)
+}
+
+func (l *slogLogger) Info(msg string, fields ...Field) {
+	l.log(context.Background(), LevelInfo, msg, fields)
+}
+
+func (l *slogLogger) Warn(msg string, fields ...Field) {
+	l.log(context.Background(), LevelWarn, msg, fields)
+}
+
+func (l *slogLogger) Error(msg string, fields ...Field) {
+	l.log(context.Background(), LevelError, msg, fields)
+}
+
+func (l *slogLogger) DebugContext(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, LevelDebug, msg, fields)
+}
+
+func (l *slogLogger) InfoContext(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, LevelInfo, msg, fields)
+}
+
+func (l *slogLogger) WarnContext(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, LevelWarn, msg, fields)
+}
+
+func (l *slogLogger) ErrorContext(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, LevelError, msg, fields)
+}
+
+// Sync 在 h 实现了 Sync 时调用它，否则直接返回。
+func (l *slogLogger) Sync() error {
+	if s, ok := l.h.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// log 构造记录并交给 h，所有导出方法直接调用 log，保证记录的调用位置是其调用方。
+func (l *slogLogger) log(ctx context.Context, level Level, msg string, fields []Field) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	slogLevel := toSlogLevel(level)
+	if !l.h.Enabled(ctx, slogLevel) {
+		return
+	}
+	var pcs [1]uintptr
+	// 跳过 runtime.Callers、log 与导出方法
+	runtime.Callers(3, pcs[:])
+	l.handle(ctx, slogLevel, time.Now(), pcs[0], msg, fields)
+}
+
+// logRecord 以给定的时间与调用位置输出日志，使 slog 记录经两次桥接后保持来源不变。
+func (l *slogLogger) logRecord(ctx context.Context, level Level, t time.Time, pc uintptr, msg string, fields []Field) {
+	slogLevel := toSlogLevel(level)
+	if !l.h.Enabled(ctx, slogLevel) {
+		return
+	}
+	l.handle(ctx, slogLevel, t, pc, msg, fields)
+}
+
+func (l *slogLogger) handle(ctx context.Context, level slog.Level, t time.Time, pc uintptr, msg string, fields []Field) {
+	r := slog.NewRecord(t, level, msg, pc)
+	r.AddAttrs(toAttrs(toAttrs(nil, ContextFields(ctx)), fields)...)
+	_ = l.h.Handle(ctx, r)
+}
+
+func toAttrs(attrs []slog.Attr, fields []Field) []slog.Attr {
+	for _, f := range fields {
+		attrs = append(attrs, slog.Any(f.Key, f.Value))
+	}
+	return attrs
+}
+
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+var (
+	_ Logger       = (*slogLogger)(nil)
+	_ sourceLogger = (*slogLogger)(nil)
+)
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "slog.log")
+	zl, err := NewZapLogger(ZapConfig{Filepath: logPath, Level: LevelInfo})
+	require.NoError(t, err)
+
+	sl := slog.New(NewSlogHandler(zl)).With("app", "game").WithGroup("ws")
+	assert.False(t, sl.Enabled(context.Background(), slog.LevelDebug))
+	sl.Debug("hidden")
+
+	ctx := WithTraceID(context.Background(), "trace-1")
+	sl.With("conn", 3).InfoContext(ctx, "login",
+		"player", "p1",
+		slog.Group("req", "id", 7, slog.Group("empty")),
+		slog.Group("", "inline", true),
+		slog.Attr{},
+	)
+	sl.Error("failed", "error", errors.New("boom"))
+	require.NoError(t, zl.Sync())
+
+	records := readLogRecords(t, logPath)
+	require.Len(t, records, 2)
+	login := records[0]
+	assert.Equal(t, "login", login["msg"])
+	assert.Equal(t, "info", login["level"])
+	assert.Equal(t, "game", login["app"])
+	assert.Equal(t, float64(3), login["ws.conn"])
+	assert.Equal(t, "p1", login["ws.player"])
+	assert.Equal(t, float64(7), login["ws.req.id"])
+	assert.Equal(t, true, login["ws.inline"])
+	assert.Equal(t, "trace-1", login[TraceIDKey])
+	assert.NotContains(t, login, "ws.req.empty")
+	assert.True(t, hasRecord(records, "failed", "ws.error", "boom"))
+}
+
+func TestSlogHandlerSource(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "source.log")
+	zl, err := NewZapLogger(ZapConfig{Filepath: logPath, Level: LevelInfo})
+	require.NoError(t, err)
+
+	h := NewSlogHandler(zl)
+	slog.New(h).Info("via_slog")
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, h.Handle(context.Background(), slog.NewRecord(ts, slog.LevelWarn, "no_pc", 0)))
+	require.NoError(t, zl.Sync())
+
+	records := readLogRecords(t, logPath)
+	require.Len(t, records, 2)
+	caller, _ := records[0]["caller"].(string)
+	assert.True(t, strings.HasPrefix(caller, "logger/slog_test.go:"), caller)
+	assert.Equal(t, "2024-01-02T03:04:05.000Z", records[1]["ts"])
+	assert.NotContains(t, records[1], "caller")
+
+	// 其他 Logger 以 source 字段输出调用位置
+	rec := &fieldRecorder{Logger: Nop()}
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	require.NoError(t, NewSlogHandler(rec).Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "m", pcs[0])))
+	require.Len(t, rec.fields, 1)
+	assert.Equal(t, "source", rec.fields[0].Key)
+	assert.Contains(t, rec.fields[0].Value, "slog_test.go:")
+}
+
+// fieldRecorder 记录最近一次 Log 的字段。
+type fieldRecorder struct {
+	Logger
+	fields []Field
+}
+
+func (r *fieldRecorder) Log(_ context.Context, _ Level, _ string, fields ...Field) {
+	r.fields = fields
+}
+
+func TestFromSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true})
+	l := FromSlogHandler(h).With(Field{Key: "app", Value: "game"}).WithGroup("ws")
+
+	assert.False(t, l.Enabled(context.Background(), LevelDebug))
+	l.Debug("hidden")
+	ctx := WithRequestID(context.Background(), "req-1")
+	l.With(Field{Key: "conn", Value: 3}).WarnContext(ctx, "slow", Field{Key: "ms", Value: 120})
+	require.NoError(t, l.Sync())
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "slow", record["msg"])
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "game", record["app"])
+	assert.Equal(t, map[string]any{"conn": float64(3), RequestIDKey: "req-1", "ms": float64(120)}, record["ws"])
+	source, ok := record["source"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "slog_test.go", filepath.Base(source["file"].(string)))
+
+	assert.Equal(t, Nop(), FromSlogHandler(nil))
+
+	// 经两次桥接后记录的时间与调用位置不变
+	buf.Reset()
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	require.NoError(t, NewSlogHandler(FromSlogHandler(h)).Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "bridged", pcs[0])))
+	record = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "2024-01-02T03:04:05Z", record["time"])
+	source, ok = record["source"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "slog_test.go", filepath.Base(source["file"].(string)))
+}
+
+func TestSlogLevels(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		assert.Equal(t, level, fromSlogLevel(toSlogLevel(level)))
+	}
+	assert.Equal(t, LevelDebug, fromSlogLevel(slog.LevelDebug-4))
+	assert.Equal(t, LevelInfo, fromSlogLevel(slog.LevelInfo+2))
+	assert.Equal(t, LevelError, fromSlogLevel(slog.LevelError+4))
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -44,6 +45,68 @@ func TestLoggerEnabled(t *testing.T) {
 	assert.True(t, l.Enabled(context.Background(), LevelWarn))
 }
 
+func TestZapLoggerOutputs(t *testing.T) {
+	dir := t.TempDir()
+	allPath := filepath.Join(dir, "all.log")
+	errPath := filepath.Join(dir, "error.log")
+	stdout := captureFile(t, &os.Stdout)
+
+	l, err := NewZapLogger(ZapConfig{
+		Filepath: allPath,
+		Level:    LevelDebug,
+		Outputs: []ZapOutput{
+			{Type: OutputFile},
+			{Type: OutputFile, Filepath: errPath, Level: LevelError},
+			{Type: OutputStdout, Encoding: EncodingConsole, Level: LevelInfo},
+		},
+	})
+	require.NoError(t, err)
+
+	l.Debug("debug_msg")
+	l.Info("info_msg", Field{Key: "k", Value: "v"})
+	l.Error("error_msg")
+	require.NoError(t, l.Sync())
+
+	all := readLogRecords(t, allPath)
+	assert.Len(t, all, 3)
+	errs := readLogRecords(t, errPath)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "error_msg", errs[0]["msg"])
+
+	out := stdout()
+	assert.NotContains(t, out, "debug_msg")
+	assert.Contains(t, out, "INFO")
+	assert.Contains(t, out, "info_msg")
+	assert.Contains(t, out, `{"k": "v"}`)
+	assert.Contains(t, out, "error_msg")
+	assert.NotContains(t, out, "\x1b[")
+}
+
+func TestZapLoggerConsoleColor(t *testing.T) {
+	stderr := captureFile(t, &os.Stderr)
+	l, err := NewZapLogger(ZapConfig{
+		Outputs: []ZapOutput{{Type: OutputStderr, Encoding: EncodingConsole, Color: true}},
+	})
+	require.NoError(t, err)
+
+	l.Warn("colored")
+	require.NoError(t, l.Sync())
+	out := stderr()
+	assert.Contains(t, out, "\x1b[")
+	assert.Contains(t, out, "colored")
+}
+
+func TestZapLoggerInvalidOutputs(t *testing.T) {
+	_, err := NewZapLogger(ZapConfig{})
+	assert.Equal(t, errEmptyLogPath, err)
+
+	_, err = NewZapLogger(ZapConfig{Outputs: []ZapOutput{{Type: "syslog"}}})
+	assert.ErrorIs(t, err, errInvalidOutput)
+
+	_, err = NewZapLogger(ZapConfig{Outputs: []ZapOutput{{Type: OutputStdout, Encoding: "xml"}}})
+	assert.ErrorIs(t, err, errInvalidEncoding)
+}
+
 func TestRegistryUsage(t *testing.T) {
 	resetRegistry()
 
@@ -123,3 +186,25 @@ func hasRecord(records []map[string]any, msg string, key string, val any) bool {
 	}
 	return false
 }
+
+// captureFile 将 *f 替换为管道，返回的函数恢复原值并读出全部写入内容。
+func captureFile(t *testing.T, f **os.File) func() string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	orig := *f
+	*f = w
+	restore := func() { *f = orig }
+	t.Cleanup(restore)
+
+	done := make(chan string, 1)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	return func() string {
+		restore()
+		require.NoError(t, w.Close())
+		return <-done
+	}
+}
@@ -3,18 +3,64 @@ package logger
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var errEmptyLogPath = errors.New("logger: log file path is empty")
+var (
+	errEmptyLogPath    = errors.New("logger: log file path is empty")
+	errInvalidOutput   = errors.New("logger: invalid output type")
+	errInvalidEncoding = errors.New("logger: invalid encoding")
+)
+
+// OutputType 表示日志输出目标。
+type OutputType string
+
+const (
+	// OutputFile 表示按 lumberjack 滚动的日志文件。
+	OutputFile OutputType = "file"
+	// OutputStdout 表示标准输出。
+	OutputStdout OutputType = "stdout"
+	// OutputStderr 表示标准错误。
+	OutputStderr OutputType = "stderr"
+)
+
+// Encoding 表示日志编码格式。
+type Encoding string
+
+const (
+	// EncodingJSON 表示每行一条 JSON 记录。
+	EncodingJSON Encoding = "json"
+	// EncodingConsole 表示便于阅读的控制台格式。
+	EncodingConsole Encoding = "console"
+)
+
+// ZapOutput 定义单个日志输出。
+type ZapOutput struct {
+	// Type 表示输出目标，为空时视为 OutputFile。
+	Type OutputType
+	// Encoding 表示编码格式，为空时视为 EncodingJSON。
+	Encoding Encoding
+	// Level 表示该输出的最低等级，实际等级取其与 Logger 等级中较高者。
+	Level Level
+	// Filepath 表示文件输出的路径，为空时使用 ZapConfig.Filepath。
+	Filepath string
+	// Color 表示 console 编码是否按等级着色。
+	Color bool
+}
 
 // ZapConfig 定义基于 zap 与 lumberjack 的日志配置。
 type ZapConfig struct {
 	// Filepath 表示日志文件路径。
 	Filepath string
+	// Outputs 表示日志输出列表，为空时以 JSON 写入 Filepath。
+	Outputs []ZapOutput
 	// Level 表示日志输出等级。
 	Level Level
 	// MaxSize 表示单个日志文件的最大大小，单位为 MB。
@@ -25,39 +71,144 @@ type ZapConfig struct {
 	MaxAge int
 	// Compress 表示是否压缩旧日志文件。
 	Compress bool
+	// Async 表示异步写入配置，启用后每个输出目标拥有独立的缓冲区。
+	Async AsyncConfig
 }
 
 // ZapLogger 提供基于 zap 的 Logger 实现。
 type ZapLogger struct {
 	base  *zap.Logger
+	level zap.AtomicLevel
 	group string
+	async []*asyncWriter
 }
 
 // NewZapLogger 创建一个基于 zap 与 lumberjack 的 Logger 实例。
 func NewZapLogger(cfg ZapConfig) (*ZapLogger, error) {
-	if cfg.Filepath == "" {
-		return nil, errEmptyLogPath
-	}
 	if cfg.MaxSize <= 0 {
 		cfg.MaxSize = 100
 	}
-	level := toZapLevel(cfg.Level)
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []ZapOutput{{Type: OutputFile, Encoding: EncodingJSON}}
+	}
+	async, err := cfg.Async.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	level := zap.NewAtomicLevelAt(toZapLevel(cfg.Level))
+
+	// 同一目标的多个输出共用一个写入器，避免并发滚动同一文件或交错写出。
+	writers := make(map[string]zapcore.WriteSyncer)
+	asyncWriters := make(map[zapcore.WriteSyncer]*asyncWriter)
+	var started []*asyncWriter
+	cores := make([]zapcore.Core, 0, len(outputs))
+	for _, out := range outputs {
+		writer, err := cfg.outputWriter(out, writers)
+		if err != nil {
+			return nil, err
+		}
+		encoder, err := newEncoder(out)
+		if err != nil {
+			return nil, err
+		}
+		minLevel := toZapLevel(out.Level)
+		enabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return lvl >= minLevel && level.Enabled(lvl)
+		})
+		if !cfg.Async.Enabled {
+			cores = append(cores, zapcore.NewCore(encoder, writer, enabler))
+			continue
+		}
+		aw, ok := asyncWriters[writer]
+		if !ok {
+			aw = newAsyncWriter(writer, async)
+			asyncWriters[writer] = aw
+			started = append(started, aw)
+		}
+		cores = append(cores, &asyncCore{LevelEnabler: enabler, enc: encoder, out: aw})
+	}
 
-	writer := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   cfg.Filepath,
-		MaxSize:    cfg.MaxSize,
-		MaxBackups: cfg.MaxBackups,
-		MaxAge:     cfg.MaxAge,
-		Compress:   cfg.Compress,
-	})
+	// 全部输出校验通过后再启动后台 goroutine
+	for _, aw := range started {
+		aw.start()
+	}
+	base := zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.AddCallerSkip(1))
+	return &ZapLogger{base: base, level: level, async: started}, nil
+}
 
-	encoderCfg := zap.NewProductionEncoderConfig()
-	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	encoder := zapcore.NewJSONEncoder(encoderCfg)
+// outputWriter 返回输出对应的写入目标。
+func (cfg ZapConfig) outputWriter(out ZapOutput, writers map[string]zapcore.WriteSyncer) (zapcore.WriteSyncer, error) {
+	switch out.Type {
+	case "", OutputFile:
+		path := out.Filepath
+		if path == "" {
+			path = cfg.Filepath
+		}
+		if path == "" {
+			return nil, errEmptyLogPath
+		}
+		if writer, ok := writers[path]; ok {
+			return writer, nil
+		}
+		writer := zapcore.AddSync(&lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    cfg.MaxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+		})
+		writers[path] = writer
+		return writer, nil
+	case OutputStdout, OutputStderr:
+		key := "<" + string(out.Type) + ">"
+		if writer, ok := writers[key]; ok {
+			return writer, nil
+		}
+		f := os.Stdout
+		if out.Type == OutputStderr {
+			f = os.Stderr
+		}
+		writer := consoleWriter(f)
+		writers[key] = writer
+		return writer, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errInvalidOutput, out.Type)
+	}
+}
 
-	core := zapcore.NewCore(encoder, writer, level)
-	base := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
-	return &ZapLogger{base: base}, nil
+// consoleWriter 包装标准输出或标准错误。终端与管道上的 fsync 会返回 EINVAL，
+// 因此只保留写入能力，Sync 不做任何事。
+func consoleWriter(f *os.File) zapcore.WriteSyncer {
+	return zapcore.Lock(zapcore.AddSync(struct{ io.Writer }{f}))
+}
+
+func newEncoder(out ZapOutput) (zapcore.Encoder, error) {
+	switch out.Encoding {
+	case "", EncodingJSON:
+		encoderCfg := zap.NewProductionEncoderConfig()
+		encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		return zapcore.NewJSONEncoder(encoderCfg), nil
+	case EncodingConsole:
+		encoderCfg := zap.NewDevelopmentEncoderConfig()
+		encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		if out.Color {
+			encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		return zapcore.NewConsoleEncoder(encoderCfg), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errInvalidEncoding, out.Encoding)
+	}
+}
+
+// SetLevel 在运行时调整日志输出等级，对派生自同一实例的 Logger 同时生效。
+func (l *ZapLogger) SetLevel(level Level) {
+	l.level.SetLevel(toZapLevel(level))
+}
+
+// Level 返回当前日志输出等级。
+func (l *ZapLogger) Level() Level {
+	return fromZapLevel(l.level.Level())
 }
 
 // With 返回附加字段后的 Logger，便于上下文透传。
@@ -67,7 +218,9 @@ func (l *ZapLogger) With(fields ...Field) Logger {
 	}
 	return &ZapLogger{
 		base:  l.base.With(toZapFields(l.group, fields)...),
+		level: l.level,
 		group: l.group,
+		async: l.async,
 	}
 }
 
@@ -82,7 +235,9 @@ func (l *ZapLogger) WithGroup(name string) Logger {
 	}
 	return &ZapLogger{
 		base:  l.base,
+		level: l.level,
 		group: group,
+		async: l.async,
 	}
 }
 
@@ -91,12 +246,43 @@ func (l *ZapLogger) Enabled(_ context.Context, level Level) bool {
 	return l.base.Core().Enabled(toZapLevel(level))
 }
 
-// Log 按等级记录日志（与 slog 对齐，必须带 ctx）。
+// Log 按等级记录日志（与 slog 对齐，必须带 ctx），ctx 携带的字段见 ContextFields。
 func (l *ZapLogger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
 	if !l.Enabled(ctx, level) {
 		return
 	}
-	l.base.Log(toZapLevel(level), msg, toZapFields(l.group, fields)...)
+	l.base.Log(toZapLevel(level), msg, l.zapFields(ctx, fields)...)
+}
+
+// logRecord 以记录自带的时间与调用位置输出日志，供 slog 桥接使用。
+func (l *ZapLogger) logRecord(ctx context.Context, level Level, t time.Time, pc uintptr, msg string, fields []Field) {
+	ce := l.base.Check(toZapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+	if !t.IsZero() {
+		ce.Time = t
+	}
+	ce.Caller = zapcore.EntryCaller{}
+	if frame, ok := sourceFrame(pc); ok {
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       pc,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+	ce.Write(l.zapFields(ctx, fields)...)
+}
+
+// zapFields 转换字段，ctx 携带的字段排在最前且不受分组影响。
+func (l *ZapLogger) zapFields(ctx context.Context, fields []Field) []zap.Field {
+	zapFields := toZapFields(l.group, fields)
+	if ctxFields := ContextFields(ctx); len(ctxFields) > 0 {
+		zapFields = append(toZapFields("", ctxFields), zapFields...)
+	}
+	return zapFields
 }
 
 // Debug 记录调试级日志（无 ctx）。
@@ -139,11 +325,33 @@ func (l *ZapLogger) ErrorContext(ctx context.Context, msg string, fields ...Fiel
 	l.Log(ctx, LevelError, msg, fields...)
 }
 
-// Sync 刷新缓冲并落盘（若实现需要）。
+// Sync 刷新缓冲并落盘（若实现需要），异步写入时先写出缓冲区中的全部日志。
 func (l *ZapLogger) Sync() error {
 	return l.base.Sync()
 }
 
+// Dropped 返回异步写入因缓冲区写满而丢弃的日志条数，对派生自同一实例的 Logger 共享。
+func (l *ZapLogger) Dropped() uint64 {
+	var n uint64
+	for _, aw := range l.async {
+		n += aw.dropped.Load()
+	}
+	return n
+}
+
+// Close 写出异步缓冲区中的剩余日志并停止后台 goroutine，之后的日志同步写出。
+// 未启用异步写入时等同于 Sync。
+func (l *ZapLogger) Close() error {
+	if len(l.async) == 0 {
+		return l.Sync()
+	}
+	errs := make([]error, 0, len(l.async))
+	for _, aw := range l.async {
+		errs = append(errs, aw.Close())
+	}
+	return errors.Join(errs...)
+}
+
 func toZapLevel(level Level) zapcore.Level {
 	switch level {
 	case LevelDebug:
@@ -159,6 +367,19 @@ func toZapLevel(level Level) zapcore.Level {
 	}
 }
 
+func fromZapLevel(level zapcore.Level) Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return LevelDebug
+	case level == zapcore.InfoLevel:
+		return LevelInfo
+	case level == zapcore.WarnLevel:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
 func toZapFields(group string, fields []Field) []zap.Field {
 	if len(fields) == 0 {
 		return nil
@@ -178,4 +399,8 @@ func toZapFields(group string, fields []Field) []zap.Field {
 	return zapFields
 }
 
-var _ Logger = (*ZapLogger)(nil)
+var (
+	_ Logger          = (*ZapLogger)(nil)
+	_ LevelController = (*ZapLogger)(nil)
+	_ sourceLogger    = (*ZapLogger)(nil)
+)
@@ -3,6 +3,8 @@ package module
 import "context"
 
 // Module 定义可插拔模块的生命周期。
+// 各生命周期方法应在 ctx 结束时尽快返回。超时后应用不再等待该调用，
+// 但在对同一组件执行下一次生命周期调用（如 Drain 之后的 Stop）前，会在其超时内等待它返回。
 type Module interface {
 	// ID 返回模块的唯一标识。
 	ID() string
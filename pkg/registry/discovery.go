@@ -0,0 +1,391 @@
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/lk2023060901/zeus-go/pkg/app"
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/etcd"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/service"
+)
+
+// DiscoveryID 表示发现服务的 ID，同时也是其配置段名称。
+const DiscoveryID = "discovery"
+
+// Discovery 为每个被查询的服务维护一份实时的实例视图：
+// 首次查询时监听服务前缀并拉取全量实例，此后由监听事件增量更新，
+// 并按 ResyncInterval 周期性全量同步，弥补监听断开期间丢失的事件。
+type Discovery struct {
+	backend backend
+	opts    options
+
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	views  map[string]*view
+	resync *conc.Future[struct{}]
+}
+
+// NewDiscovery 创建服务发现。
+func NewDiscovery(client *etcd.Client, opts ...Option) *Discovery {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var b backend
+	if client != nil {
+		b = etcdBackend{client: client}
+	}
+	return &Discovery{
+		backend: b,
+		opts:    o,
+		views:   make(map[string]*view),
+	}
+}
+
+// ID 返回服务 ID。
+func (d *Discovery) ID() string {
+	if d.opts.id != "" {
+		return d.opts.id
+	}
+	return DiscoveryID
+}
+
+// Requires 返回依赖的服务列表，发现服务不依赖其他服务。
+func (d *Discovery) Requires() []string {
+	return nil
+}
+
+// Configure 从与 ID 同名的配置段（默认为 discovery）读取配置。
+func (d *Discovery) Configure(dec app.ConfigDecoder) error {
+	return dec.Decode(&d.opts.config)
+}
+
+// Init 校验依赖。
+func (d *Discovery) Init(_ context.Context) error {
+	if d.backend == nil {
+		return errNilClient
+	}
+	return nil
+}
+
+// Start 启动周期性全量同步。
+func (d *Discovery) Start(_ context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.resync != nil || d.opts.config.ResyncInterval <= 0 {
+		return nil
+	}
+	ctx := d.lifetime()
+	d.resync = conc.Go(func() (struct{}, error) {
+		for sleepContext(ctx, d.opts.config.ResyncInterval) {
+			d.Resync(ctx)
+		}
+		return struct{}{}, nil
+	})
+	return nil
+}
+
+// Stop 停止所有监听与同步，已订阅的回调不再被调用。
+func (d *Discovery) Stop(_ context.Context) error {
+	d.mu.Lock()
+	cancel, resync, views := d.cancel, d.resync, d.views
+	d.ctx, d.cancel, d.resync = nil, nil, nil
+	d.views = make(map[string]*view)
+	d.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	if resync != nil {
+		resync.Await()
+	}
+	for _, v := range views {
+		d.backend.unwatch(v.prefix)
+	}
+	return nil
+}
+
+// Instances 返回服务当前的实例列表，按实例 ID 排序。
+func (d *Discovery) Instances(ctx context.Context, name string) ([]Instance, error) {
+	v, err := d.view(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return v.list(), nil
+}
+
+// Subscribe 订阅服务实例的变化：fn 先以当前实例列表调用一次，此后每次变化时以完整列表调用。
+// 同一订阅的回调串行执行，返回的函数用于取消订阅。
+func (d *Discovery) Subscribe(ctx context.Context, name string, fn func([]Instance)) (func(), error) {
+	v, err := d.view(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return v.subscribe(fn), nil
+}
+
+// Resync 对所有已监听的服务执行一次全量同步，并在监听已断开时重新监听。
+func (d *Discovery) Resync(ctx context.Context) {
+	d.mu.Lock()
+	views := make([]*view, 0, len(d.views))
+	for _, v := range d.views {
+		views = append(views, v)
+	}
+	d.mu.Unlock()
+
+	for _, v := range views {
+		if err := d.sync(ctx, v); err != nil {
+			d.opts.logger.Warn("service resync failed",
+				logger.Field{Key: "service", Value: v.name},
+				logger.Field{Key: "error", Value: err},
+			)
+		}
+	}
+}
+
+// view 返回服务的实例视图，首次查询时完成监听与全量同步。
+func (d *Discovery) view(ctx context.Context, name string) (*view, error) {
+	if name == "" {
+		return nil, errEmptyServiceName
+	}
+	if d.backend == nil {
+		return nil, errNilClient
+	}
+
+	d.mu.Lock()
+	v, ok := d.views[name]
+	if !ok {
+		v = newView(name, serviceKey(d.opts.config.Prefix, name), d.opts.logger)
+		d.views[name] = v
+	}
+	d.mu.Unlock()
+
+	if v.isSynced() {
+		return v, nil
+	}
+	if err := d.sync(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// sync 确保服务前缀处于监听状态，并以全量实例校正视图。
+func (d *Discovery) sync(ctx context.Context, v *view) error {
+	d.mu.Lock()
+	lifetime := d.lifetime()
+	d.mu.Unlock()
+
+	v.syncMu.Lock()
+	defer v.syncMu.Unlock()
+
+	// 先监听再拉取，保证拉取期间的变化不会丢失
+	if err := d.backend.watch(lifetime, v.prefix, true, v.apply, func(err error) {
+		// 监听在下一次全量同步时重新建立
+		d.opts.logger.Warn("service watch closed",
+			logger.Field{Key: "service", Value: v.name},
+			logger.Field{Key: "error", Value: err},
+		)
+	}); err != nil {
+		return err
+	}
+	v.beginSync()
+	kvs, err := d.backend.list(ctx, v.prefix)
+	if err != nil {
+		v.abortSync()
+		return err
+	}
+	v.merge(kvs)
+	return nil
+}
+
+// lifetime 返回监听使用的上下文，调用方需持有 d.mu。
+func (d *Discovery) lifetime() context.Context {
+	if d.ctx == nil {
+		d.ctx, d.cancel = context.WithCancel(context.Background())
+	}
+	return d.ctx
+}
+
+// view 表示单个服务的实例视图。
+type view struct {
+	name   string
+	prefix string
+	logger logger.Logger
+
+	// syncMu 串行化全量同步
+	syncMu sync.Mutex
+	// notifyMu 串行化回调，保证订阅者最后收到的总是最新列表
+	notifyMu sync.Mutex
+
+	mu        sync.Mutex
+	synced    bool
+	instances map[string]Instance
+	// revs 记录每个键最近一次变化的版本，包括已删除的键
+	revs map[string]int64
+	// touched 记录全量同步期间由监听事件变化过的键，非同步期间为 nil
+	touched map[string]struct{}
+	subs    map[uint64]func([]Instance)
+	nextSub uint64
+}
+
+func newView(name, prefix string, l logger.Logger) *view {
+	return &view{
+		name:      name,
+		prefix:    prefix,
+		logger:    l,
+		instances: make(map[string]Instance),
+		revs:      make(map[string]int64),
+		subs:      make(map[uint64]func([]Instance)),
+	}
+}
+
+func (v *view) isSynced() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.synced
+}
+
+// apply 处理监听事件，忽略早于已知版本的事件。
+func (v *view) apply(ev *etcd.WatchEvent) {
+	v.mu.Lock()
+	if ev.Revision < v.revs[ev.Key] {
+		v.mu.Unlock()
+		return
+	}
+	v.revs[ev.Key] = ev.Revision
+	if v.touched != nil {
+		v.touched[ev.Key] = struct{}{}
+	}
+	switch ev.Type {
+	case etcd.EventTypePut:
+		inst, err := decodeInstance(ev.Value)
+		if err != nil {
+			v.mu.Unlock()
+			v.logger.Warn("ignore invalid instance",
+				logger.Field{Key: "key", Value: ev.Key},
+				logger.Field{Key: "error", Value: err},
+			)
+			return
+		}
+		v.instances[ev.Key] = inst
+	case etcd.EventTypeDelete:
+		delete(v.instances, ev.Key)
+	}
+	v.mu.Unlock()
+	v.notify()
+}
+
+func (v *view) beginSync() {
+	v.mu.Lock()
+	v.touched = make(map[string]struct{})
+	v.mu.Unlock()
+}
+
+func (v *view) abortSync() {
+	v.mu.Lock()
+	v.touched = nil
+	v.mu.Unlock()
+}
+
+// merge 以全量结果校正视图：跳过已被更新事件覆盖的键，
+// 并删除不在全量结果中、且同步期间未被事件变化过的键。
+func (v *view) merge(kvs []*etcd.KeyValue) {
+	v.mu.Lock()
+	seen := make(map[string]struct{}, len(kvs))
+	for _, kv := range kvs {
+		seen[kv.Key] = struct{}{}
+		if kv.ModRevision <= v.revs[kv.Key] {
+			continue
+		}
+		inst, err := decodeInstance(kv.Value)
+		if err != nil {
+			v.logger.Warn("ignore invalid instance",
+				logger.Field{Key: "key", Value: kv.Key},
+				logger.Field{Key: "error", Value: err},
+			)
+			continue
+		}
+		v.instances[kv.Key] = inst
+		v.revs[kv.Key] = kv.ModRevision
+	}
+	for key := range v.revs {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		if _, ok := v.touched[key]; ok {
+			continue
+		}
+		delete(v.instances, key)
+		delete(v.revs, key)
+	}
+	v.touched = nil
+	v.synced = true
+	v.mu.Unlock()
+	v.notify()
+}
+
+// list 返回按实例 ID 排序的实例列表。
+func (v *view) list() []Instance {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.listLocked()
+}
+
+func (v *view) listLocked() []Instance {
+	result := make([]Instance, 0, len(v.instances))
+	for _, inst := range v.instances {
+		result = append(result, inst)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+func (v *view) subscribe(fn func([]Instance)) func() {
+	v.notifyMu.Lock()
+	defer v.notifyMu.Unlock()
+
+	v.mu.Lock()
+	id := v.nextSub
+	v.nextSub++
+	v.subs[id] = fn
+	current := v.listLocked()
+	v.mu.Unlock()
+
+	fn(current)
+	return func() {
+		v.mu.Lock()
+		delete(v.subs, id)
+		v.mu.Unlock()
+	}
+}
+
+// notify 以最新实例列表调用所有订阅者。
+func (v *view) notify() {
+	v.notifyMu.Lock()
+	defer v.notifyMu.Unlock()
+
+	v.mu.Lock()
+	if len(v.subs) == 0 {
+		v.mu.Unlock()
+		return
+	}
+	current := v.listLocked()
+	subs := make([]func([]Instance), 0, len(v.subs))
+	for _, fn := range v.subs {
+		subs = append(subs, fn)
+	}
+	v.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(current)
+	}
+}
+
+var _ service.Service = (*Discovery)(nil)
@@ -0,0 +1,305 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/app"
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/etcd"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/service"
+)
+
+// RegistrarID 表示注册服务的 ID，同时也是其配置段名称。
+const RegistrarID = "registry"
+
+// Registrar 将当前进程的实例注册到 etcd，并在租约丢失后自动重新注册。
+// 实例键的监听出错结束时会重新建立监听，并按 ResyncInterval 周期性确认实例仍在注册，
+// 避免监听断开期间的删除事件丢失后实例一直处于未注册状态。
+// Registrar 实现了 service.Service 与 service.Drainer：随应用启动注册，
+// 在排空阶段先行注销，使调用方在停止前不再路由新的请求。
+type Registrar struct {
+	backend  backend
+	instance Instance
+	opts     options
+	requires []string
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	loop    *conc.Future[struct{}]
+	revoke  func()
+	lost    chan struct{}
+	running bool
+	// rewatch 重新监听当前注册的实例键，监听仍在时不做处理
+	rewatch func() error
+	// recheck 在实例键的监听出错结束时收到通知
+	recheck chan struct{}
+}
+
+// NewRegistrar 创建实例注册服务，requires 为注册服务在应用中依赖的组件 ID，
+// 通常是提供实例对外服务的组件，保证其就绪后才注册、注销后才停止。
+func NewRegistrar(client *etcd.Client, inst Instance, requires []string, opts ...Option) *Registrar {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var b backend
+	if client != nil {
+		b = etcdBackend{client: client}
+	}
+	return &Registrar{
+		backend:  b,
+		instance: inst,
+		opts:     o,
+		requires: requires,
+		recheck:  make(chan struct{}, 1),
+	}
+}
+
+// Instance 返回注册的实例。
+func (r *Registrar) Instance() Instance {
+	return r.instance
+}
+
+// ID 返回服务 ID。
+func (r *Registrar) ID() string {
+	if r.opts.id != "" {
+		return r.opts.id
+	}
+	return RegistrarID
+}
+
+// Requires 返回依赖的组件 ID 列表。
+func (r *Registrar) Requires() []string {
+	return r.requires
+}
+
+// Configure 从与 ID 同名的配置段（默认为 registry）读取配置。
+func (r *Registrar) Configure(dec app.ConfigDecoder) error {
+	return dec.Decode(&r.opts.config)
+}
+
+// Init 校验实例信息。
+func (r *Registrar) Init(_ context.Context) error {
+	if r.backend == nil {
+		return errNilClient
+	}
+	return r.instance.Validate()
+}
+
+// Start 注册实例。
+func (r *Registrar) Start(ctx context.Context) error {
+	return r.Register(ctx)
+}
+
+// Drain 注销实例，使其不再被发现。
+func (r *Registrar) Drain(ctx context.Context) error {
+	return r.Deregister(ctx)
+}
+
+// Stop 注销实例。
+func (r *Registrar) Stop(ctx context.Context) error {
+	return r.Deregister(ctx)
+}
+
+// Register 注册实例并在后台保持注册状态，已注册时直接返回。
+func (r *Registrar) Register(ctx context.Context) error {
+	if err := r.instance.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return nil
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	if err := r.register(ctx, loopCtx); err != nil {
+		cancel()
+		return err
+	}
+	r.cancel = cancel
+	r.running = true
+	r.loop = conc.Go(func() (struct{}, error) {
+		r.keepRegistered(loopCtx)
+		return struct{}{}, nil
+	})
+	return nil
+}
+
+// Deregister 删除实例并撤销租约，未注册时直接返回。
+func (r *Registrar) Deregister(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return nil
+	}
+	cancel, loop := r.cancel, r.loop
+	r.cancel, r.loop = nil, nil
+	r.running = false
+	r.mu.Unlock()
+
+	cancel()
+	loop.Await()
+
+	r.mu.Lock()
+	revoke := r.revoke
+	r.revoke = nil
+	r.mu.Unlock()
+
+	err := r.backend.delete(ctx, instanceKey(r.opts.config.Prefix, r.instance))
+	if revoke != nil {
+		revoke()
+	}
+	if err == nil {
+		r.opts.logger.Info("instance deregistered", r.fields()...)
+	}
+	return err
+}
+
+// register 申请租约、写入实例并监听实例键，lifetime 决定租约续约与监听的生命周期。
+// 调用方需持有 r.mu，或保证不存在并发调用。
+func (r *Registrar) register(ctx, lifetime context.Context) error {
+	value, err := encodeInstance(r.instance)
+	if err != nil {
+		return err
+	}
+	key := instanceKey(r.opts.config.Prefix, r.instance)
+
+	lease, revoke, err := r.backend.grant(lifetime, r.opts.config.TTL)
+	if err != nil {
+		return err
+	}
+	if err := r.backend.put(ctx, key, value, lease); err != nil {
+		revoke()
+		return err
+	}
+
+	lost := make(chan struct{})
+	var once sync.Once
+	watchCtx, cancelWatch := context.WithCancel(lifetime)
+	rewatch := func() error {
+		return r.backend.watch(watchCtx, key, false, func(ev *etcd.WatchEvent) {
+			if ev.Type == etcd.EventTypeDelete {
+				once.Do(func() { close(lost) })
+			}
+		}, r.watchClosed)
+	}
+	if err := rewatch(); err != nil {
+		cancelWatch()
+		r.backend.unwatch(key)
+		revoke()
+		return err
+	}
+
+	r.revoke = func() {
+		cancelWatch()
+		r.backend.unwatch(key)
+		revoke()
+	}
+	r.lost = lost
+	r.rewatch = rewatch
+	r.opts.logger.Info("instance registered", r.fields()...)
+	return nil
+}
+
+// watchClosed 在实例键的监听出错结束时记录日志，并通知 keepRegistered 重新监听与检查。
+func (r *Registrar) watchClosed(err error) {
+	r.opts.logger.Warn("instance watch closed",
+		append(r.fields(), logger.Field{Key: "error", Value: err})...)
+	select {
+	case r.recheck <- struct{}{}:
+	default:
+	}
+}
+
+// keepRegistered 在实例键被删除（租约过期或被外部删除）后重新注册，直到 ctx 取消。
+// 监听出错结束或每隔 ResyncInterval 时重新监听并确认实例键仍然存在。
+func (r *Registrar) keepRegistered(ctx context.Context) {
+	var tick <-chan time.Time
+	if interval := r.opts.config.ResyncInterval; interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		r.mu.Lock()
+		lost := r.lost
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-lost:
+		case <-r.recheck:
+			if r.registered(ctx) {
+				continue
+			}
+		case <-tick:
+			if r.registered(ctx) {
+				continue
+			}
+		}
+
+		r.opts.logger.Warn("instance registration lost, re-registering", r.fields()...)
+		for {
+			r.mu.Lock()
+			if r.revoke != nil {
+				r.revoke()
+				r.revoke = nil
+			}
+			err := r.register(ctx, ctx)
+			r.mu.Unlock()
+			if err == nil || ctx.Err() != nil {
+				break
+			}
+			r.opts.logger.Error("instance register failed",
+				append(r.fields(), logger.Field{Key: "error", Value: err})...)
+			if !sleepContext(ctx, r.opts.config.RetryInterval) {
+				return
+			}
+		}
+	}
+}
+
+// registered 重新建立实例键的监听并确认实例键仍然存在，无法确认时视为存在。
+func (r *Registrar) registered(ctx context.Context) bool {
+	r.mu.Lock()
+	rewatch := r.rewatch
+	r.mu.Unlock()
+
+	if err := rewatch(); err != nil {
+		r.opts.logger.Warn("instance rewatch failed",
+			append(r.fields(), logger.Field{Key: "error", Value: err})...)
+	}
+	key := instanceKey(r.opts.config.Prefix, r.instance)
+	kvs, err := r.backend.list(ctx, key)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.opts.logger.Warn("instance check failed",
+				append(r.fields(), logger.Field{Key: "error", Value: err})...)
+		}
+		return true
+	}
+	for _, kv := range kvs {
+		if kv.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Registrar) fields() []logger.Field {
+	return []logger.Field{
+		{Key: "service", Value: r.instance.Name},
+		{Key: "instance", Value: r.instance.ID},
+		{Key: "address", Value: r.instance.Address},
+	}
+}
+
+var _ service.Drainer = (*Registrar)(nil)
+var _ service.Service = (*Registrar)(nil)
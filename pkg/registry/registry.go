@@ -0,0 +1,196 @@
+// Package registry 基于 etcd 提供服务实例注册与发现。
+//
+// 实例以 JSON 形式写入 <prefix>/<服务名>/<实例 ID>，并绑定自动续约的租约：
+// 进程退出或失联后租约过期，实例随之自动下线。
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/etcd"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+// DefaultPrefix 表示默认的注册根路径。
+const DefaultPrefix = "/zeus/services"
+
+var (
+	errEmptyServiceName = errors.New("registry: service name is empty")
+	errEmptyInstanceID  = errors.New("registry: instance id is empty")
+	errEmptyAddress     = errors.New("registry: instance address is empty")
+	errNilClient        = errors.New("registry: etcd client is nil")
+)
+
+// Instance 表示一个服务实例。
+type Instance struct {
+	// ID 表示实例在服务内的唯一标识。
+	ID string `json:"id"`
+	// Name 表示服务名称。
+	Name string `json:"name"`
+	// Address 表示实例对外提供服务的地址，例如 10.0.0.1:9000。
+	Address string `json:"address"`
+	// Version 表示实例版本。
+	Version string `json:"version,omitempty"`
+	// Weight 表示负载均衡权重，0 表示使用默认权重。
+	Weight int `json:"weight,omitempty"`
+	// Metadata 表示实例的自定义元数据，例如区服、机房。
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate 校验实例的必填字段。
+func (i Instance) Validate() error {
+	switch {
+	case i.Name == "":
+		return errEmptyServiceName
+	case i.ID == "":
+		return errEmptyInstanceID
+	case i.Address == "":
+		return errEmptyAddress
+	}
+	return nil
+}
+
+// Config 注册与发现配置。
+type Config struct {
+	// Prefix 表示注册根路径。
+	Prefix string `yaml:"prefix"`
+	// TTL 表示实例租约的过期时间（秒）。
+	TTL int64 `yaml:"ttl"`
+	// RetryInterval 表示注册失败或租约丢失后重新注册的间隔。
+	RetryInterval time.Duration `yaml:"retry_interval"`
+	// ResyncInterval 表示发现端全量重新同步、注册端检查实例是否仍在注册的间隔，
+	// 0 表示不做周期性检查。
+	ResyncInterval time.Duration `yaml:"resync_interval"`
+}
+
+// DefaultConfig 返回默认配置。
+func DefaultConfig() Config {
+	return Config{
+		Prefix:         DefaultPrefix,
+		TTL:            10,
+		RetryInterval:  time.Second,
+		ResyncInterval: 30 * time.Second,
+	}
+}
+
+// Option 注册与发现选项。
+type Option func(*options)
+
+type options struct {
+	id     string
+	config Config
+	logger logger.Logger
+}
+
+func defaultOptions() options {
+	return options{
+		config: DefaultConfig(),
+		logger: logger.Nop(),
+	}
+}
+
+// WithConfig 设置注册与发现配置。
+func WithConfig(cfg Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithID 设置服务在应用中的 ID，同时也是其配置段名称，默认为 RegistrarID 或 DiscoveryID。
+// 同一应用中注册多个实例或发现服务时用于区分。
+func WithID(id string) Option {
+	return func(o *options) {
+		o.id = id
+	}
+}
+
+// WithLogger 设置日志记录器。
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// serviceKey 返回服务下所有实例的键前缀。
+func serviceKey(prefix, name string) string {
+	return strings.TrimSuffix(prefix, "/") + "/" + name + "/"
+}
+
+// instanceKey 返回实例的键。
+func instanceKey(prefix string, inst Instance) string {
+	return serviceKey(prefix, inst.Name) + inst.ID
+}
+
+func encodeInstance(inst Instance) (string, error) {
+	raw, err := json.Marshal(inst)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func decodeInstance(raw []byte) (Instance, error) {
+	var inst Instance
+	if err := json.Unmarshal(raw, &inst); err != nil {
+		return Instance{}, fmt.Errorf("registry: decode instance: %w", err)
+	}
+	return inst, nil
+}
+
+// backend 抽象注册与发现依赖的 etcd 操作，便于替换为内存实现。
+type backend interface {
+	grant(ctx context.Context, ttl int64) (etcd.LeaseID, func(), error)
+	put(ctx context.Context, key, value string, lease etcd.LeaseID) error
+	delete(ctx context.Context, key string) error
+	list(ctx context.Context, prefix string) ([]*etcd.KeyValue, error)
+	// watch 监听键或前缀，同一个键只保留一个监听；监听出错结束时调用 onClose
+	watch(ctx context.Context, key string, prefix bool, handler func(*etcd.WatchEvent), onClose func(error)) error
+	unwatch(key string)
+}
+
+// etcdBackend 基于 etcd.KV、etcd.Lease 与 etcd.Watcher 实现 backend。
+type etcdBackend struct {
+	client *etcd.Client
+}
+
+func (b etcdBackend) grant(ctx context.Context, ttl int64) (etcd.LeaseID, func(), error) {
+	return b.client.Lease().GrantWithKeepAlive(ctx, ttl)
+}
+
+func (b etcdBackend) put(ctx context.Context, key, value string, lease etcd.LeaseID) error {
+	return b.client.KV().PutWithLease(ctx, key, value, lease)
+}
+
+func (b etcdBackend) delete(ctx context.Context, key string) error {
+	_, err := b.client.KV().Delete(ctx, key)
+	return err
+}
+
+func (b etcdBackend) list(ctx context.Context, prefix string) ([]*etcd.KeyValue, error) {
+	return b.client.KV().GetWithPrefix(ctx, prefix)
+}
+
+func (b etcdBackend) watch(ctx context.Context, key string, prefix bool, handler func(*etcd.WatchEvent), onClose func(error)) error {
+	return b.client.Watcher().WatchWithCloseHandler(ctx, key, prefix, handler, onClose)
+}
+
+func (b etcdBackend) unwatch(key string) {
+	b.client.Watcher().StopWatch(key)
+}
+
+// sleepContext 等待 d 或 ctx 取消，ctx 取消时返回 false。
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
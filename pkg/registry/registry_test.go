@@ -0,0 +1,419 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lk2023060901/zeus-go/pkg/etcd"
+)
+
+// memoryBackend 是同步派发监听事件的内存 backend。
+type memoryBackend struct {
+	mu      sync.Mutex
+	rev     int64
+	lease   etcd.LeaseID
+	kvs     map[string]*etcd.KeyValue
+	leases  map[etcd.LeaseID]bool
+	watches map[string]*memoryWatch
+}
+
+type memoryWatch struct {
+	ctx     context.Context
+	prefix  bool
+	handler func(*etcd.WatchEvent)
+	onClose func(error)
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{
+		kvs:     make(map[string]*etcd.KeyValue),
+		leases:  make(map[etcd.LeaseID]bool),
+		watches: make(map[string]*memoryWatch),
+	}
+}
+
+func (b *memoryBackend) grant(_ context.Context, _ int64) (etcd.LeaseID, func(), error) {
+	b.mu.Lock()
+	b.lease++
+	id := b.lease
+	b.leases[id] = true
+	b.mu.Unlock()
+	return id, func() { b.expire(id) }, nil
+}
+
+func (b *memoryBackend) put(_ context.Context, key, value string, lease etcd.LeaseID) error {
+	b.mu.Lock()
+	b.rev++
+	b.kvs[key] = &etcd.KeyValue{Key: key, Value: []byte(value), ModRevision: b.rev, Lease: int64(lease)}
+	ev := &etcd.WatchEvent{Type: etcd.EventTypePut, Key: key, Value: []byte(value), Revision: b.rev}
+	b.mu.Unlock()
+	b.dispatch(ev)
+	return nil
+}
+
+func (b *memoryBackend) delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	if _, ok := b.kvs[key]; !ok {
+		b.mu.Unlock()
+		return nil
+	}
+	b.rev++
+	delete(b.kvs, key)
+	ev := &etcd.WatchEvent{Type: etcd.EventTypeDelete, Key: key, Revision: b.rev}
+	b.mu.Unlock()
+	b.dispatch(ev)
+	return nil
+}
+
+func (b *memoryBackend) list(_ context.Context, prefix string) ([]*etcd.KeyValue, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var result []*etcd.KeyValue
+	for key, kv := range b.kvs {
+		if strings.HasPrefix(key, prefix) {
+			copied := *kv
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// watch 与 etcd.Watcher 一致：同一个键只保留一个监听。
+func (b *memoryBackend) watch(ctx context.Context, key string, prefix bool, handler func(*etcd.WatchEvent), onClose func(error)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if w, ok := b.watches[key]; ok && w.ctx.Err() == nil {
+		return nil
+	}
+	b.watches[key] = &memoryWatch{ctx: ctx, prefix: prefix, handler: handler, onClose: onClose}
+	return nil
+}
+
+// breakWatch 模拟监听出错结束：移除监听并调用 onClose。
+func (b *memoryBackend) breakWatch(key string, err error) {
+	b.mu.Lock()
+	w := b.watches[key]
+	delete(b.watches, key)
+	b.mu.Unlock()
+	if w != nil && w.onClose != nil {
+		w.onClose(err)
+	}
+}
+
+// drop 在不派发监听事件的情况下删除键，模拟监听断开期间丢失的删除事件。
+func (b *memoryBackend) drop(key string) {
+	b.mu.Lock()
+	delete(b.kvs, key)
+	b.mu.Unlock()
+}
+
+func (b *memoryBackend) watching(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	w, ok := b.watches[key]
+	return ok && w.ctx.Err() == nil
+}
+
+func (b *memoryBackend) unwatch(key string) {
+	b.mu.Lock()
+	delete(b.watches, key)
+	b.mu.Unlock()
+}
+
+// expire 模拟租约过期：删除绑定在租约上的所有键。
+func (b *memoryBackend) expire(id etcd.LeaseID) {
+	b.mu.Lock()
+	delete(b.leases, id)
+	var keys []string
+	for key, kv := range b.kvs {
+		if etcd.LeaseID(kv.Lease) == id {
+			keys = append(keys, key)
+		}
+	}
+	b.mu.Unlock()
+	for _, key := range keys {
+		_ = b.delete(context.Background(), key)
+	}
+}
+
+func (b *memoryBackend) dispatch(ev *etcd.WatchEvent) {
+	b.mu.Lock()
+	var handlers []func(*etcd.WatchEvent)
+	for key, w := range b.watches {
+		if w.ctx.Err() != nil {
+			continue
+		}
+		if key == ev.Key || (w.prefix && strings.HasPrefix(ev.Key, key)) {
+			handlers = append(handlers, w.handler)
+		}
+	}
+	b.mu.Unlock()
+	for _, h := range handlers {
+		h(ev)
+	}
+}
+
+func (b *memoryBackend) get(key string) *etcd.KeyValue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.kvs[key]
+}
+
+func (b *memoryBackend) activeLeases() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.leases)
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.RetryInterval = time.Millisecond
+	cfg.ResyncInterval = 0
+	return cfg
+}
+
+func newTestRegistrar(b backend, inst Instance) *Registrar {
+	r := NewRegistrar(nil, inst, []string{"grpc"}, WithConfig(testConfig()))
+	r.backend = b
+	return r
+}
+
+func newTestDiscovery(b backend) *Discovery {
+	d := NewDiscovery(nil, WithConfig(testConfig()))
+	d.backend = b
+	return d
+}
+
+func gameInstance(id, addr string) Instance {
+	return Instance{
+		ID:       id,
+		Name:     "game",
+		Address:  addr,
+		Version:  "1.2.0",
+		Weight:   10,
+		Metadata: map[string]string{"zone": "1"},
+	}
+}
+
+func ids(insts []Instance) []string {
+	result := make([]string, 0, len(insts))
+	for _, inst := range insts {
+		result = append(result, inst.ID)
+	}
+	return result
+}
+
+func TestInstanceValidate(t *testing.T) {
+	assert.NoError(t, gameInstance("g1", "10.0.0.1:9000").Validate())
+	assert.ErrorIs(t, Instance{ID: "g1", Address: "a"}.Validate(), errEmptyServiceName)
+	assert.ErrorIs(t, Instance{Name: "game", Address: "a"}.Validate(), errEmptyInstanceID)
+	assert.ErrorIs(t, Instance{Name: "game", ID: "g1"}.Validate(), errEmptyAddress)
+	assert.Equal(t, "/zeus/services/game/g1", instanceKey(DefaultPrefix+"/", gameInstance("g1", "a")))
+}
+
+func TestRegistrar(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend()
+	inst := gameInstance("g1", "10.0.0.1:9000")
+	r := newTestRegistrar(b, inst)
+	key := instanceKey(DefaultPrefix, inst)
+
+	assert.Equal(t, RegistrarID, r.ID())
+	assert.Equal(t, []string{"grpc"}, r.Requires())
+	assert.ErrorIs(t, NewRegistrar(nil, inst, nil).Init(ctx), errNilClient)
+	require.NoError(t, r.Init(ctx))
+	require.NoError(t, r.Start(ctx))
+	require.NoError(t, r.Start(ctx))
+
+	kv := b.get(key)
+	require.NotNil(t, kv)
+	got, err := decodeInstance(kv.Value)
+	require.NoError(t, err)
+	assert.Equal(t, inst, got)
+	assert.Equal(t, 1, b.activeLeases())
+
+	// 租约过期后重新注册
+	b.expire(etcd.LeaseID(kv.Lease))
+	require.Eventually(t, func() bool {
+		kv := b.get(key)
+		return kv != nil && etcd.LeaseID(kv.Lease) != etcd.LeaseID(1)
+	}, time.Second, time.Millisecond)
+
+	// 被外部删除后同样重新注册
+	require.NoError(t, b.delete(ctx, key))
+	require.Eventually(t, func() bool { return b.get(key) != nil }, time.Second, time.Millisecond)
+
+	// 排空时注销并撤销租约，随后的 Stop 不再处理
+	require.NoError(t, r.Drain(ctx))
+	assert.Nil(t, b.get(key))
+	assert.Equal(t, 0, b.activeLeases())
+	require.NoError(t, r.Stop(ctx))
+}
+
+func TestRegistrarWatchClosed(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend()
+	inst := gameInstance("g1", "10.0.0.1:9000")
+	r := newTestRegistrar(b, inst)
+	key := instanceKey(DefaultPrefix, inst)
+	require.NoError(t, r.Start(ctx))
+	defer func() { _ = r.Stop(ctx) }()
+
+	// 监听出错结束后重新监听，并发现断开期间丢失的删除
+	b.drop(key)
+	b.breakWatch(key, errors.New("stream reset"))
+	require.Eventually(t, func() bool { return b.get(key) != nil && b.watching(key) }, time.Second, time.Millisecond)
+
+	// 重新建立的监听继续生效
+	require.NoError(t, b.delete(ctx, key))
+	require.Eventually(t, func() bool { return b.get(key) != nil }, time.Second, time.Millisecond)
+}
+
+func TestRegistrarResync(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend()
+	inst := gameInstance("g1", "10.0.0.1:9000")
+	cfg := testConfig()
+	cfg.ResyncInterval = 5 * time.Millisecond
+	r := NewRegistrar(nil, inst, nil, WithConfig(cfg), WithID("registry.game"))
+	r.backend = b
+	key := instanceKey(DefaultPrefix, inst)
+
+	assert.Equal(t, "registry.game", r.ID())
+	require.NoError(t, r.Start(ctx))
+	defer func() { _ = r.Stop(ctx) }()
+
+	// 未收到删除事件时由周期性检查发现并重新注册
+	b.drop(key)
+	require.Eventually(t, func() bool { return b.get(key) != nil }, time.Second, time.Millisecond)
+}
+
+func TestRegistrarInvalidInstance(t *testing.T) {
+	r := newTestRegistrar(newMemoryBackend(), Instance{Name: "game"})
+	assert.ErrorIs(t, r.Init(context.Background()), errEmptyInstanceID)
+	assert.ErrorIs(t, r.Register(context.Background()), errEmptyInstanceID)
+}
+
+func TestDiscovery(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend()
+	g1 := newTestRegistrar(b, gameInstance("g1", "10.0.0.1:9000"))
+	require.NoError(t, g1.Register(ctx))
+
+	d := newTestDiscovery(b)
+	assert.Equal(t, DiscoveryID, d.ID())
+	require.NoError(t, d.Init(ctx))
+	require.NoError(t, d.Start(ctx))
+
+	_, err := d.Instances(ctx, "")
+	assert.ErrorIs(t, err, errEmptyServiceName)
+
+	insts, err := d.Instances(ctx, "game")
+	require.NoError(t, err)
+	require.Len(t, insts, 1)
+	assert.Equal(t, gameInstance("g1", "10.0.0.1:9000"), insts[0])
+
+	var mu sync.Mutex
+	var updates [][]string
+	last := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return updates[len(updates)-1]
+	}
+	unsubscribe, err := d.Subscribe(ctx, "game", func(insts []Instance) {
+		mu.Lock()
+		updates = append(updates, ids(insts))
+		mu.Unlock()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g1"}, last())
+
+	// 新实例上线
+	g2 := newTestRegistrar(b, gameInstance("g2", "10.0.0.2:9000"))
+	require.NoError(t, g2.Register(ctx))
+	assert.Equal(t, []string{"g1", "g2"}, last())
+
+	// 实例下线
+	require.NoError(t, g1.Deregister(ctx))
+	assert.Equal(t, []string{"g2"}, last())
+	insts, err = d.Instances(ctx, "game")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g2"}, ids(insts))
+
+	// 其他服务的实例不影响视图
+	other := newTestRegistrar(b, Instance{ID: "c1", Name: "chat", Address: "10.0.0.3:9000"})
+	require.NoError(t, other.Register(ctx))
+	assert.Equal(t, []string{"g2"}, last())
+
+	// 取消订阅后不再回调
+	unsubscribe()
+	count := len(updates)
+	require.NoError(t, g2.Deregister(ctx))
+	assert.Len(t, updates, count)
+
+	require.NoError(t, d.Stop(ctx))
+}
+
+func TestDiscoveryResync(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend()
+	d := newTestDiscovery(b)
+
+	require.NoError(t, b.put(ctx, serviceKey(DefaultPrefix, "game")+"g1", `{"id":"g1","name":"game","address":"a"}`, 0))
+	insts, err := d.Instances(ctx, "game")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g1"}, ids(insts))
+
+	// 监听断开期间的变化由全量同步补齐
+	b.unwatch(serviceKey(DefaultPrefix, "game"))
+	require.NoError(t, b.delete(ctx, serviceKey(DefaultPrefix, "game")+"g1"))
+	require.NoError(t, b.put(ctx, serviceKey(DefaultPrefix, "game")+"g2", `{"id":"g2","name":"game","address":"b"}`, 0))
+	insts, err = d.Instances(ctx, "game")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g1"}, ids(insts))
+
+	d.Resync(ctx)
+	insts, err = d.Instances(ctx, "game")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g2"}, ids(insts))
+
+	// 全量同步重新建立了监听
+	require.NoError(t, b.put(ctx, serviceKey(DefaultPrefix, "game")+"g3", `{"id":"g3","name":"game","address":"c"}`, 0))
+	insts, err = d.Instances(ctx, "game")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g2", "g3"}, ids(insts))
+}
+
+func TestViewMerge(t *testing.T) {
+	v := newView("game", "/s/game/", nil)
+	put := func(key, id string, rev int64) *etcd.WatchEvent {
+		return &etcd.WatchEvent{
+			Type:     etcd.EventTypePut,
+			Key:      key,
+			Value:    []byte(`{"id":"` + id + `","name":"game","address":"a"}`),
+			Revision: rev,
+		}
+	}
+	v.apply(put("/s/game/g1", "g1", 1))
+	v.apply(put("/s/game/g2", "g2", 2))
+
+	// 同步期间：g1 被更新的事件覆盖，g3 由事件新增，g2 已被删除
+	v.beginSync()
+	v.apply(put("/s/game/g1", "g1-new", 5))
+	v.apply(put("/s/game/g3", "g3", 6))
+	v.merge([]*etcd.KeyValue{
+		{Key: "/s/game/g1", Value: []byte(`{"id":"g1-old","name":"game","address":"a"}`), ModRevision: 4},
+	})
+	assert.Equal(t, []string{"g1-new", "g3"}, ids(v.list()))
+
+	// 过期的事件被忽略
+	v.apply(&etcd.WatchEvent{Type: etcd.EventTypeDelete, Key: "/s/game/g3", Revision: 3})
+	assert.Equal(t, []string{"g1-new", "g3"}, ids(v.list()))
+}
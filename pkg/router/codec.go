@@ -0,0 +1,45 @@
+package router
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var errNilMessage = errors.New("router: message is nil")
+
+// AnyCodec 以 anypb.Any 作为传输层消息，类型键为 proto 消息的完整名称，例如 game.v1.LoginReq。
+// 适用于消息类型为 google.protobuf.Any 的 gRPC 双向流。
+type AnyCodec struct{}
+
+// Key 返回 Any 中消息的完整名称。
+func (AnyCodec) Key(msg *anypb.Any) (string, error) {
+	if msg == nil {
+		return "", errNilMessage
+	}
+	return string(msg.MessageName()), nil
+}
+
+// KeyOf 返回 proto 消息的完整名称。
+func (AnyCodec) KeyOf(msg proto.Message) (string, error) {
+	if msg == nil {
+		return "", errNilMessage
+	}
+	return string(msg.ProtoReflect().Descriptor().FullName()), nil
+}
+
+// Decode 将 Any 中的消息解码到 into，类型不一致时返回错误。
+func (AnyCodec) Decode(msg *anypb.Any, into proto.Message) error {
+	return msg.UnmarshalTo(into)
+}
+
+// Encode 将 proto 消息包装为 Any。
+func (AnyCodec) Encode(msg proto.Message) (*anypb.Any, error) {
+	if msg == nil {
+		return nil, errNilMessage
+	}
+	return anypb.New(msg)
+}
+
+var _ Codec[*anypb.Any, string] = AnyCodec{}
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/session"
+)
+
+var (
+	// ErrUnauthenticated 表示会话未认证，不能发送该类型的消息。
+	ErrUnauthenticated = errors.New("router: unauthenticated")
+	// ErrRateLimited 表示会话发送消息过快。
+	ErrRateLimited = errors.New("router: rate limited")
+)
+
+// Logging 记录每条消息的类型键、会话、耗时与处理结果：成功为 Debug，失败为 Warn。
+// 会话 ID 随会话的 ctx 传给 Logger 输出。
+func Logging[T any, K comparable](l logger.Logger) Middleware[T, K] {
+	return func(next HandlerFunc[T, K]) HandlerFunc[T, K] {
+		return func(c *Context[T, K]) error {
+			start := time.Now()
+			err := next(c)
+			level := logger.LevelDebug
+			if err != nil {
+				level = logger.LevelWarn
+			}
+			ctx := c.Session.Context()
+			if !l.Enabled(ctx, level) {
+				return err
+			}
+			fields := []logger.Field{
+				{Key: "message", Value: fmt.Sprint(c.Key)},
+				{Key: "peer", Value: c.Session.RemoteAddr()},
+				{Key: "duration", Value: time.Since(start)},
+			}
+			if err != nil {
+				fields = append(fields, logger.Field{Key: "error", Value: err})
+			}
+			l.Log(ctx, level, "session message", fields...)
+			return err
+		}
+	}
+}
+
+// Auth 要求会话通过认证才能发送消息，public 中的类型键（例如登录请求）无需认证。
+// authenticated 判断会话是否已认证，通常检查登录处理函数写入的会话属性。
+func Auth[T any, K comparable](authenticated func(s *session.Session[T]) bool, public ...K) Middleware[T, K] {
+	return func(next HandlerFunc[T, K]) HandlerFunc[T, K] {
+		return func(c *Context[T, K]) error {
+			if !slices.Contains(public, c.Key) && !authenticated(c.Session) {
+				return fmt.Errorf("%w: %v", ErrUnauthenticated, c.Key)
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimiterSeq 为每个限流中间件生成独立的会话属性键。
+var rateLimiterSeq atomic.Uint64
+
+// RateLimit 以令牌桶限制每个会话的消息速率：每秒补充 rate 个令牌，最多积累 burst 个，
+// 令牌不足时返回 ErrRateLimited。
+func RateLimit[T any, K comparable](rate float64, burst int) Middleware[T, K] {
+	key := fmt.Sprintf("router.ratelimit.%d", rateLimiterSeq.Add(1))
+	return func(next HandlerFunc[T, K]) HandlerFunc[T, K] {
+		return func(c *Context[T, K]) error {
+			// 同一会话的消息由 Serve 串行分发，令牌桶无需加锁
+			b, _ := c.Session.Get(key)
+			bucket, ok := b.(*tokenBucket)
+			if !ok {
+				bucket = &tokenBucket{tokens: float64(burst), last: time.Now()}
+				c.Session.Set(key, bucket)
+			}
+			if !bucket.take(rate, burst, time.Now()) {
+				return fmt.Errorf("%w: %v", ErrRateLimited, c.Key)
+			}
+			return next(c)
+		}
+	}
+}
+
+// tokenBucket 表示单个会话的令牌桶。
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(rate float64, burst int, now time.Time) bool {
+	b.tokens = min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
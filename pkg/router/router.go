@@ -0,0 +1,207 @@
+// Package router 按消息类型将会话收到的消息分发给类型化的处理函数，取代围绕 Recv 的 switch 分支。
+//
+// 路由器通过 Codec 从传输层消息中取出类型键并解码为具体的 proto 消息，
+// gRPC 流可直接使用以 anypb.Any 为载体的 AnyCodec，网关连接使用按消息 ID 编码的帧。
+package router
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lk2023060901/zeus-go/pkg/session"
+)
+
+var (
+	// ErrUnknownMessage 表示消息类型没有注册处理函数。
+	ErrUnknownMessage = errors.New("router: unknown message")
+	// ErrDuplicateHandler 表示消息类型已注册处理函数。
+	ErrDuplicateHandler = errors.New("router: duplicate handler")
+
+	errAbstractMessage = errors.New("router: handler message type must be a concrete proto message")
+)
+
+// Codec 在传输层消息与 proto 消息之间转换。
+type Codec[T any, K comparable] interface {
+	// Key 返回传输层消息的类型键。
+	Key(msg T) (K, error)
+	// KeyOf 返回 proto 消息类型对应的类型键。
+	KeyOf(msg proto.Message) (K, error)
+	// Decode 将传输层消息的负载解码到 into。
+	Decode(msg T, into proto.Message) error
+	// Encode 将 proto 消息编码为传输层消息。
+	Encode(msg proto.Message) (T, error)
+}
+
+// Context 表示一次消息分发的上下文。
+type Context[T any, K comparable] struct {
+	// Session 表示收到消息的会话。
+	Session *session.Session[T]
+	// Key 表示消息的类型键。
+	Key K
+	// Raw 表示未解码的传输层消息。
+	Raw T
+
+	router *Router[T, K]
+}
+
+// Reply 编码 msg 并发送给当前会话。
+func (c *Context[T, K]) Reply(msg proto.Message) error {
+	return c.router.Send(c.Session, msg)
+}
+
+// HandlerFunc 处理一条消息，返回的错误交由路由器的错误处理函数处理。
+type HandlerFunc[T any, K comparable] func(c *Context[T, K]) error
+
+// Middleware 包装处理函数，例如认证、限流与日志。
+type Middleware[T any, K comparable] func(next HandlerFunc[T, K]) HandlerFunc[T, K]
+
+// Router 按类型键分发消息，可被多个会话并发使用。
+//
+//	r := router.New(router.AnyCodec{})
+//	r.Use(router.Logging[*anypb.Any, string](l))
+//	_ = router.Handle(r, func(c *router.Context[*anypb.Any, string], req *pb.LoginReq) error {
+//		return c.Reply(&pb.LoginResp{})
+//	})
+//	return sess.Serve(r.Dispatch)
+type Router[T any, K comparable] struct {
+	codec Codec[T, K]
+
+	mu          sync.RWMutex
+	routes      map[K]HandlerFunc[T, K]
+	middlewares []Middleware[T, K]
+	notFound    HandlerFunc[T, K]
+	onError     func(c *Context[T, K], err error) error
+	// chains 与 fallback 是包装了全部中间件的处理函数，在注册时组合，Dispatch 直接使用
+	chains   map[K]HandlerFunc[T, K]
+	fallback HandlerFunc[T, K]
+}
+
+// New 创建路由器。
+func New[T any, K comparable](codec Codec[T, K]) *Router[T, K] {
+	return &Router[T, K]{
+		codec:    codec,
+		routes:   make(map[K]HandlerFunc[T, K]),
+		chains:   make(map[K]HandlerFunc[T, K]),
+		fallback: unknownMessage[T, K],
+	}
+}
+
+// Handle 为消息类型 M 注册处理函数，类型键由 Codec.KeyOf 确定。
+func Handle[M proto.Message, T any, K comparable](r *Router[T, K], h func(c *Context[T, K], msg M) error) error {
+	var zero M
+	if any(zero) == nil {
+		return errAbstractMessage
+	}
+	key, err := r.codec.KeyOf(zero)
+	if err != nil {
+		return err
+	}
+	return r.HandleFunc(key, func(c *Context[T, K]) error {
+		msg := zero.ProtoReflect().Type().New().Interface().(M)
+		if err := r.codec.Decode(c.Raw, msg); err != nil {
+			return fmt.Errorf("router: decode %v: %w", c.Key, err)
+		}
+		return h(c, msg)
+	})
+}
+
+// HandleFunc 为类型键注册未解码的处理函数。
+func (r *Router[T, K]) HandleFunc(key K, h HandlerFunc[T, K]) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.routes[key]; exists {
+		return fmt.Errorf("%w: %v", ErrDuplicateHandler, key)
+	}
+	r.routes[key] = h
+	r.chains[key] = r.chain(h)
+	return nil
+}
+
+// Use 追加中间件，先追加的中间件在外层执行；中间件同样作用于未知消息。
+func (r *Router[T, K]) Use(mws ...Middleware[T, K]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, mws...)
+	for key, h := range r.routes {
+		r.chains[key] = r.chain(h)
+	}
+	r.fallback = r.chain(r.notFoundHandler())
+}
+
+// NotFound 设置未注册消息的处理函数，默认返回 ErrUnknownMessage。
+func (r *Router[T, K]) NotFound(h HandlerFunc[T, K]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notFound = h
+	r.fallback = r.chain(r.notFoundHandler())
+}
+
+// OnError 设置处理函数返回错误时的处理方式：fn 返回 nil 时会话继续，
+// 返回错误时会话以该错误关闭。默认原样返回错误，即关闭会话。
+func (r *Router[T, K]) OnError(fn func(c *Context[T, K], err error) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onError = fn
+}
+
+// Dispatch 分发一条消息，签名与 session.Handler 一致，可直接传给 Session.Serve。
+func (r *Router[T, K]) Dispatch(s *session.Session[T], msg T) error {
+	key, err := r.codec.Key(msg)
+	if err != nil {
+		return err
+	}
+	c := &Context[T, K]{Session: s, Key: key, Raw: msg, router: r}
+
+	r.mu.RLock()
+	h, ok := r.chains[key]
+	if !ok {
+		h = r.fallback
+	}
+	onError := r.onError
+	r.mu.RUnlock()
+
+	if err := h(c); err != nil {
+		if onError != nil {
+			return onError(c, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// chain 用全部中间件包装 h，调用方需持有 r.mu。
+func (r *Router[T, K]) chain(h HandlerFunc[T, K]) HandlerFunc[T, K] {
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+	return h
+}
+
+// notFoundHandler 返回未注册消息的处理函数，调用方需持有 r.mu。
+func (r *Router[T, K]) notFoundHandler() HandlerFunc[T, K] {
+	if r.notFound != nil {
+		return r.notFound
+	}
+	return unknownMessage[T, K]
+}
+
+func unknownMessage[T any, K comparable](c *Context[T, K]) error {
+	return fmt.Errorf("%w: %v", ErrUnknownMessage, c.Key)
+}
+
+// Encode 将 proto 消息编码为传输层消息，便于通过 Manager 推送或广播。
+func (r *Router[T, K]) Encode(msg proto.Message) (T, error) {
+	return r.codec.Encode(msg)
+}
+
+// Send 编码 msg 并发送给会话。
+func (r *Router[T, K]) Send(s *session.Session[T], msg proto.Message) error {
+	raw, err := r.codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+	return s.Send(raw)
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/session"
+)
+
+// pipe 是测试用的传输层：in 为对端发来的消息，out 记录写出的消息。
+type pipe struct {
+	in  chan *anypb.Any
+	out chan *anypb.Any
+}
+
+func newPipe() *pipe {
+	return &pipe{in: make(chan *anypb.Any, 16), out: make(chan *anypb.Any, 16)}
+}
+
+func (p *pipe) Send(msg *anypb.Any) error {
+	p.out <- msg
+	return nil
+}
+
+func (p *pipe) Recv() (*anypb.Any, error) {
+	msg, ok := <-p.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func (p *pipe) Close() error {
+	return nil
+}
+
+func (p *pipe) RemoteAddr() string {
+	return "127.0.0.1:1234"
+}
+
+type testContext = Context[*anypb.Any, string]
+
+func openSession(t *testing.T) (*session.Session[*anypb.Any], *pipe) {
+	t.Helper()
+	p := newPipe()
+	s, err := session.NewManager[*anypb.Any]().Open(context.Background(), "", p)
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+	return s, p
+}
+
+func dispatch(t *testing.T, r *Router[*anypb.Any, string], s *session.Session[*anypb.Any], msg proto.Message) error {
+	t.Helper()
+	raw, err := r.Encode(msg)
+	require.NoError(t, err)
+	return r.Dispatch(s, raw)
+}
+
+func reply(t *testing.T, p *pipe) proto.Message {
+	t.Helper()
+	select {
+	case raw := <-p.out:
+		msg, err := raw.UnmarshalNew()
+		require.NoError(t, err)
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no reply")
+		return nil
+	}
+}
+
+func TestRouterDispatch(t *testing.T) {
+	r := New[*anypb.Any, string](AnyCodec{})
+	require.NoError(t, Handle(r, func(c *testContext, msg *wrapperspb.StringValue) error {
+		assert.Equal(t, "google.protobuf.StringValue", c.Key)
+		return c.Reply(wrapperspb.String("echo " + msg.GetValue()))
+	}))
+	assert.ErrorIs(t, Handle(r, func(*testContext, *wrapperspb.StringValue) error { return nil }), ErrDuplicateHandler)
+	assert.ErrorIs(t, Handle(r, func(*testContext, proto.Message) error { return nil }), errAbstractMessage)
+
+	s, p := openSession(t)
+	require.NoError(t, dispatch(t, r, s, wrapperspb.String("hi")))
+	assert.True(t, proto.Equal(wrapperspb.String("echo hi"), reply(t, p)))
+
+	// 未注册的消息默认返回错误
+	assert.ErrorIs(t, dispatch(t, r, s, &emptypb.Empty{}), ErrUnknownMessage)
+
+	var unknown string
+	r.NotFound(func(c *testContext) error {
+		unknown = c.Key
+		return nil
+	})
+	require.NoError(t, dispatch(t, r, s, &emptypb.Empty{}))
+	assert.Equal(t, "google.protobuf.Empty", unknown)
+}
+
+func TestRouterServe(t *testing.T) {
+	r := New[*anypb.Any, string](AnyCodec{})
+	boom := errors.New("boom")
+	require.NoError(t, Handle(r, func(c *testContext, msg *wrapperspb.Int64Value) error {
+		if msg.GetValue() < 0 {
+			return boom
+		}
+		return c.Reply(wrapperspb.Int64(msg.GetValue() * 2))
+	}))
+
+	var handled []error
+	r.OnError(func(_ *testContext, err error) error {
+		handled = append(handled, err)
+		if errors.Is(err, boom) {
+			return err
+		}
+		return nil
+	})
+
+	s, p := openSession(t)
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(r.Dispatch)
+	}()
+
+	send := func(msg proto.Message) {
+		raw, err := r.Encode(msg)
+		require.NoError(t, err)
+		p.in <- raw
+	}
+	send(wrapperspb.Int64(21))
+	assert.True(t, proto.Equal(wrapperspb.Int64(42), reply(t, p)))
+
+	// 未知消息被错误处理函数忽略，会话继续
+	send(&emptypb.Empty{})
+	send(wrapperspb.Int64(1))
+	assert.True(t, proto.Equal(wrapperspb.Int64(2), reply(t, p)))
+
+	send(wrapperspb.Int64(-1))
+	assert.ErrorIs(t, <-served, boom)
+	require.Len(t, handled, 2)
+	assert.ErrorIs(t, handled[0], ErrUnknownMessage)
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	r := New[*anypb.Any, string](AnyCodec{})
+	var order []string
+	trace := func(name string) Middleware[*anypb.Any, string] {
+		return func(next HandlerFunc[*anypb.Any, string]) HandlerFunc[*anypb.Any, string] {
+			return func(c *testContext) error {
+				order = append(order, name)
+				return next(c)
+			}
+		}
+	}
+	r.Use(trace("outer"), trace("inner"), Logging[*anypb.Any, string](logger.Nop()))
+	require.NoError(t, Handle(r, func(*testContext, *emptypb.Empty) error {
+		order = append(order, "handler")
+		return nil
+	}))
+
+	s, _ := openSession(t)
+	require.NoError(t, dispatch(t, r, s, &emptypb.Empty{}))
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+
+	// 中间件链在注册时组合，分发时不再重复组合；之后追加的中间件同样生效
+	composed := 0
+	r.Use(func(next HandlerFunc[*anypb.Any, string]) HandlerFunc[*anypb.Any, string] {
+		composed++
+		return next
+	})
+	assert.Equal(t, 2, composed)
+	order = nil
+	for range 3 {
+		require.NoError(t, dispatch(t, r, s, &emptypb.Empty{}))
+	}
+	assert.Equal(t, 2, composed)
+	assert.Len(t, order, 9)
+}
+
+func TestAuth(t *testing.T) {
+	r := New[*anypb.Any, string](AnyCodec{})
+	r.Use(Auth(func(s *session.Session[*anypb.Any]) bool {
+		_, ok := s.Get("player")
+		return ok
+	}, "google.protobuf.StringValue"))
+	require.NoError(t, Handle(r, func(c *testContext, msg *wrapperspb.StringValue) error {
+		c.Session.Set("player", msg.GetValue())
+		return nil
+	}))
+	require.NoError(t, Handle(r, func(*testContext, *emptypb.Empty) error { return nil }))
+
+	s, _ := openSession(t)
+	assert.ErrorIs(t, dispatch(t, r, s, &emptypb.Empty{}), ErrUnauthenticated)
+	require.NoError(t, dispatch(t, r, s, wrapperspb.String("p1")))
+	require.NoError(t, dispatch(t, r, s, &emptypb.Empty{}))
+}
+
+func TestRateLimit(t *testing.T) {
+	r := New[*anypb.Any, string](AnyCodec{})
+	r.Use(RateLimit[*anypb.Any, string](1, 2))
+	require.NoError(t, Handle(r, func(*testContext, *emptypb.Empty) error { return nil }))
+
+	s1, _ := openSession(t)
+	s2, _ := openSession(t)
+	require.NoError(t, dispatch(t, r, s1, &emptypb.Empty{}))
+	require.NoError(t, dispatch(t, r, s1, &emptypb.Empty{}))
+	assert.ErrorIs(t, dispatch(t, r, s1, &emptypb.Empty{}), ErrRateLimited)
+
+	// 每个会话独立限流
+	require.NoError(t, dispatch(t, r, s2, &emptypb.Empty{}))
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := &tokenBucket{tokens: 1, last: now}
+	assert.True(t, b.take(10, 1, now))
+	assert.False(t, b.take(10, 1, now))
+	assert.True(t, b.take(10, 1, now.Add(100*time.Millisecond)))
+	assert.False(t, b.take(10, 1, now.Add(150*time.Millisecond)))
+	// 令牌不超过 burst
+	assert.True(t, b.take(10, 1, now.Add(10*time.Second)))
+	assert.False(t, b.take(10, 1, now.Add(10*time.Second)))
+}
@@ -0,0 +1,206 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RoundRobin 表示轮询负载均衡策略。
+	RoundRobin = "zeus_round_robin"
+	// Weighted 表示按实例权重的平滑加权轮询策略。
+	Weighted = "zeus_weighted"
+	// ConsistentHash 表示按哈希键的一致性哈希策略，相同的键总是路由到同一实例。
+	ConsistentHash = "zeus_consistent_hash"
+
+	// HashKeyHeader 表示一致性哈希键所在的请求元数据键。
+	HashKeyHeader = "x-zeus-hash-key"
+	// DefaultWeight 表示实例未设置权重时使用的权重。
+	DefaultWeight = 100
+
+	// ringReplicas 表示一致性哈希环上每个实例的虚拟节点数。
+	ringReplicas = 160
+)
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(RoundRobin, roundRobinBuilder{}, base.Config{HealthCheck: true}))
+	balancer.Register(base.NewBalancerBuilder(Weighted, weightedBuilder{}, base.Config{HealthCheck: true}))
+	balancer.Register(base.NewBalancerBuilder(ConsistentHash, consistentHashBuilder{}, base.Config{HealthCheck: true}))
+}
+
+// WithBalancer 返回使用指定负载均衡策略的拨号选项，例如 WithBalancer(ConsistentHash)。
+func WithBalancer(name string) grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, name))
+}
+
+// WithHashKey 设置一致性哈希键（例如玩家 ID），同一个键的请求总是路由到同一实例。
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, HashKeyHeader, key)
+}
+
+// hashKey 返回请求的一致性哈希键，未在出站元数据中设置时沿用入站请求的哈希键，
+// 便于中间服务按同一个键继续路由。
+func hashKey(ctx context.Context) (string, bool) {
+	var values []string
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		values = md.Get(HashKeyHeader)
+	}
+	if len(values) == 0 {
+		values = metadata.ValueFromIncomingContext(ctx, HashKeyHeader)
+	}
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// endpoint 表示一个就绪的连接及其实例信息。
+type endpoint struct {
+	conn   balancer.SubConn
+	id     string
+	weight int
+}
+
+// endpoints 返回按实例 ID（缺失时为地址）排序的就绪连接，保证各客户端构建的选择器一致。
+func endpoints(info base.PickerBuildInfo) []endpoint {
+	result := make([]endpoint, 0, len(info.ReadySCs))
+	for conn, sc := range info.ReadySCs {
+		ep := endpoint{conn: conn, id: sc.Address.Addr, weight: DefaultWeight}
+		if inst, ok := InstanceFromAddress(sc.Address); ok {
+			ep.id = inst.ID
+			if inst.Weight > 0 {
+				ep.weight = inst.Weight
+			}
+		}
+		result = append(result, ep)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].id < result[j].id
+	})
+	return result
+}
+
+type roundRobinBuilder struct{}
+
+func (roundRobinBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	eps := endpoints(info)
+	if len(eps) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &roundRobinPicker{endpoints: eps}
+	p.next.Store(rand.Uint32())
+	return p
+}
+
+// roundRobinPicker 依次选择就绪连接，起点随机以避免所有客户端同时压向同一实例。
+type roundRobinPicker struct {
+	endpoints []endpoint
+	next      atomic.Uint32
+}
+
+func (p *roundRobinPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	n := p.next.Add(1)
+	return balancer.PickResult{SubConn: p.endpoints[n%uint32(len(p.endpoints))].conn}, nil
+}
+
+type weightedBuilder struct{}
+
+func (weightedBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	eps := endpoints(info)
+	if len(eps) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	return &weightedPicker{
+		endpoints: eps,
+		current:   make([]int, len(eps)),
+	}
+}
+
+// weightedPicker 实现平滑加权轮询：每次为所有实例累加权重，选出当前值最大者并减去总权重，
+// 使高权重实例的请求均匀分散而非连续集中。
+type weightedPicker struct {
+	endpoints []endpoint
+
+	mu      sync.Mutex
+	current []int
+}
+
+func (p *weightedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	total, best := 0, 0
+	for i, ep := range p.endpoints {
+		p.current[i] += ep.weight
+		total += ep.weight
+		if p.current[i] > p.current[best] {
+			best = i
+		}
+	}
+	p.current[best] -= total
+	return balancer.PickResult{SubConn: p.endpoints[best].conn}, nil
+}
+
+type consistentHashBuilder struct{}
+
+func (consistentHashBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	eps := endpoints(info)
+	if len(eps) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &consistentHashPicker{
+		ring:     make([]ringNode, 0, len(eps)*ringReplicas),
+		fallback: &roundRobinPicker{endpoints: eps},
+	}
+	p.fallback.next.Store(rand.Uint32())
+	for _, ep := range eps {
+		for i := 0; i < ringReplicas; i++ {
+			p.ring = append(p.ring, ringNode{
+				hash: crc32.ChecksumIEEE([]byte(ep.id + "#" + strconv.Itoa(i))),
+				conn: ep.conn,
+			})
+		}
+	}
+	sort.Slice(p.ring, func(i, j int) bool {
+		return p.ring[i].hash < p.ring[j].hash
+	})
+	return p
+}
+
+type ringNode struct {
+	hash uint32
+	conn balancer.SubConn
+}
+
+// consistentHashPicker 在哈希环上选择哈希键对应的实例，虚拟节点按实例 ID 计算，
+// 实例增减时只有相邻区间的键会迁移；请求未携带哈希键时退化为轮询。
+type consistentHashPicker struct {
+	ring     []ringNode
+	fallback *roundRobinPicker
+}
+
+func (p *consistentHashPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	key, ok := hashKey(info.Ctx)
+	if !ok {
+		return p.fallback.Pick(info)
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(p.ring), func(i int) bool {
+		return p.ring[i].hash >= h
+	})
+	if i == len(p.ring) {
+		i = 0
+	}
+	return balancer.PickResult{SubConn: p.ring[i].conn}, nil
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/lk2023060901/zeus-go/pkg/registry"
+)
+
+// fakeSubConn 以名称区分的 SubConn。
+type fakeSubConn struct {
+	balancer.SubConn
+	name string
+}
+
+func buildInfo(insts ...registry.Instance) (base.PickerBuildInfo, map[balancer.SubConn]string) {
+	info := base.PickerBuildInfo{ReadySCs: make(map[balancer.SubConn]base.SubConnInfo)}
+	names := make(map[balancer.SubConn]string)
+	for _, addr := range addresses(insts) {
+		inst, _ := InstanceFromAddress(addr)
+		sc := &fakeSubConn{name: inst.ID}
+		info.ReadySCs[sc] = base.SubConnInfo{Address: addr}
+		names[sc] = inst.ID
+	}
+	return info, names
+}
+
+func instance(id string, weight int) registry.Instance {
+	return registry.Instance{
+		ID:       id,
+		Name:     "game",
+		Address:  "10.0.0." + id + ":9000",
+		Weight:   weight,
+		Metadata: map[string]string{"zone": "1"},
+	}
+}
+
+func pickN(t *testing.T, p balancer.Picker, ctx context.Context, n int, names map[balancer.SubConn]string) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		res, err := p.Pick(balancer.PickInfo{Ctx: ctx})
+		require.NoError(t, err)
+		counts[names[res.SubConn]]++
+	}
+	return counts
+}
+
+func TestTarget(t *testing.T) {
+	assert.Equal(t, "zeus://game", Target("game"))
+}
+
+func TestAddresses(t *testing.T) {
+	inst := instance("1", 30)
+	addrs := addresses([]registry.Instance{inst})
+	require.Len(t, addrs, 1)
+	assert.Equal(t, inst.Address, addrs[0].Addr)
+
+	got, ok := InstanceFromAddress(addrs[0])
+	require.True(t, ok)
+	assert.Equal(t, inst, got)
+	assert.True(t, addrs[0].BalancerAttributes.Equal(addresses([]registry.Instance{inst})[0].BalancerAttributes))
+
+	changed := inst
+	changed.Metadata = map[string]string{"zone": "2"}
+	assert.False(t, addrs[0].BalancerAttributes.Equal(addresses([]registry.Instance{changed})[0].BalancerAttributes))
+}
+
+func TestNoSubConnAvailable(t *testing.T) {
+	info, _ := buildInfo()
+	for _, b := range []base.PickerBuilder{roundRobinBuilder{}, weightedBuilder{}, consistentHashBuilder{}} {
+		_, err := b.Build(info).Pick(balancer.PickInfo{Ctx: context.Background()})
+		assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+	}
+}
+
+func TestRoundRobinPicker(t *testing.T) {
+	info, names := buildInfo(instance("1", 0), instance("2", 0), instance("3", 0))
+	counts := pickN(t, roundRobinBuilder{}.Build(info), context.Background(), 300, names)
+	assert.Equal(t, map[string]int{"1": 100, "2": 100, "3": 100}, counts)
+
+	// 计数器越过 2^31 与 2^32 时不会产生负下标
+	for _, start := range []uint32{math.MaxInt32 - 2, math.MaxUint32 - 2} {
+		p := roundRobinBuilder{}.Build(info).(*roundRobinPicker)
+		p.next.Store(start)
+		counts = pickN(t, p, context.Background(), 6, names)
+		assert.Len(t, counts, 3)
+	}
+}
+
+func TestWeightedPicker(t *testing.T) {
+	info, names := buildInfo(instance("1", 50), instance("2", 0), instance("3", 150))
+	counts := pickN(t, weightedBuilder{}.Build(info), context.Background(), 300, names)
+	assert.Equal(t, map[string]int{"1": 50, "2": 100, "3": 150}, counts)
+
+	// 平滑加权：权重 5:1:1 时高权重实例不会连续被选中 5 次
+	info, names = buildInfo(instance("1", 5), instance("2", 1), instance("3", 1))
+	p := weightedBuilder{}.Build(info)
+	var seq string
+	for i := 0; i < 7; i++ {
+		res, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+		require.NoError(t, err)
+		seq += names[res.SubConn]
+	}
+	assert.Equal(t, "1121311", seq)
+}
+
+func TestConsistentHashPicker(t *testing.T) {
+	info, names := buildInfo(instance("1", 0), instance("2", 0), instance("3", 0))
+	p := consistentHashBuilder{}.Build(info)
+
+	// 相同的键路由到同一实例，不同的键分散到所有实例
+	owners := make(map[string]string)
+	spread := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		key := "player-" + strconv.Itoa(i)
+		counts := pickN(t, p, WithHashKey(context.Background(), key), 5, names)
+		require.Len(t, counts, 1)
+		for name := range counts {
+			owners[key] = name
+			spread[name]++
+		}
+	}
+	assert.Len(t, spread, 3)
+
+	// 入站请求的哈希键同样生效
+	in := metadata.NewIncomingContext(context.Background(), metadata.Pairs(HashKeyHeader, "player-7"))
+	assert.Equal(t, map[string]int{owners["player-7"]: 3}, pickN(t, p, in, 3, names))
+
+	// 实例下线后，其余实例上的键不迁移
+	info, names = buildInfo(instance("1", 0), instance("2", 0))
+	p = consistentHashBuilder{}.Build(info)
+	for key, owner := range owners {
+		if owner == "3" {
+			continue
+		}
+		assert.Equal(t, map[string]int{owner: 1}, pickN(t, p, WithHashKey(context.Background(), key), 1, names))
+	}
+
+	// 未携带哈希键时轮询
+	assert.Equal(t, map[string]int{"1": 5, "2": 5}, pickN(t, p, context.Background(), 10, names))
+}
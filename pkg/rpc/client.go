@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+// DefaultClientTimeout 表示未设置截止时间的一元调用默认超时。
+const DefaultClientTimeout = 10 * time.Second
+
+// ClientOption gRPC 客户端选项。
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	logger   logger.Logger
+	timeout  time.Duration
+	dialOpts []grpc.DialOption
+}
+
+// WithClientLogger 设置客户端拦截器使用的日志记录器。
+func WithClientLogger(l logger.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = l
+	}
+}
+
+// WithClientTimeout 设置未设置截止时间的一元调用默认超时，0 表示不设置。
+func WithClientTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithDialOptions 追加创建连接时使用的选项，例如解析器、负载均衡策略与传输凭证。
+// 追加的拦截器排在 ClientInterceptors 标准拦截器之后执行。
+func WithDialOptions(opts ...grpc.DialOption) ClientOption {
+	return func(o *clientOptions) {
+		o.dialOpts = append(o.dialOpts, opts...)
+	}
+}
+
+// NewClient 创建带有标准客户端拦截器的 gRPC 连接：
+//
+//	conn, err := rpc.NewClient(rpc.Target("chat"),
+//		rpc.WithDialOptions(
+//			grpc.WithResolvers(rpc.NewResolver(discovery)),
+//			grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		),
+//	)
+func NewClient(target string, opts ...ClientOption) (*grpc.ClientConn, error) {
+	o := clientOptions{
+		logger:  logger.Nop(),
+		timeout: DefaultClientTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	dialOpts := append(ClientInterceptors(o.logger, o.timeout), o.dialOpts...)
+	return grpc.NewClient(target, dialOpts...)
+}
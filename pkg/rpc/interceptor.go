@@ -0,0 +1,293 @@
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+const (
+	// RequestIDHeader 表示请求 ID 所在的元数据键。
+	RequestIDHeader = "x-request-id"
+	// TraceIDHeader 表示链路追踪 ID 所在的元数据键。
+	TraceIDHeader = "x-trace-id"
+)
+
+// WithRequestID 返回携带请求 ID 的 ctx，经客户端拦截器发出的请求会透传该 ID，
+// 日志中输出为 request_id 字段，与 logger.WithRequestID 等价。
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return logger.WithRequestID(ctx, id)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求 ID。
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	return logger.RequestIDFromContext(ctx)
+}
+
+// WithTraceID 返回携带链路追踪 ID 的 ctx，经客户端拦截器发出的请求会透传该 ID，
+// 日志中输出为 trace_id 字段，与 logger.WithTraceID 等价。
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return logger.WithTraceID(ctx, id)
+}
+
+// TraceIDFromContext 返回 ctx 中的链路追踪 ID。
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	return logger.TraceIDFromContext(ctx)
+}
+
+// newID 生成随机的 128 位十六进制 ID。
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ServerInterceptors 返回标准的服务端拦截器链：元数据透传、日志、panic 恢复与一元调用超时。
+// timeout 为 0 时不限制一元调用的处理时间。
+func ServerInterceptors(l logger.Logger, timeout time.Duration) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			UnaryServerMetadata(),
+			UnaryServerLogging(l),
+			UnaryServerRecovery(l),
+			UnaryServerDeadline(timeout),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamServerMetadata(),
+			StreamServerLogging(l),
+			StreamServerRecovery(l),
+		),
+	}
+}
+
+// ClientInterceptors 返回标准的客户端拦截器链：元数据透传、一元调用超时与日志。
+// timeout 为 0 时不为一元调用设置默认超时。
+func ClientInterceptors(l logger.Logger, timeout time.Duration) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(
+			UnaryClientMetadata(),
+			UnaryClientDeadline(timeout),
+			UnaryClientLogging(l),
+		),
+		grpc.WithChainStreamInterceptor(
+			StreamClientMetadata(),
+			StreamClientLogging(l),
+		),
+	}
+}
+
+// incomingIDs 从入站元数据读取请求 ID 与链路追踪 ID 并写入 ctx，缺少请求 ID 时生成一个新的。
+func incomingIDs(ctx context.Context) context.Context {
+	requestID := lastValue(metadata.ValueFromIncomingContext(ctx, RequestIDHeader))
+	if requestID == "" {
+		requestID = newID()
+	}
+	ctx = WithRequestID(ctx, requestID)
+	if traceID := lastValue(metadata.ValueFromIncomingContext(ctx, TraceIDHeader)); traceID != "" {
+		ctx = WithTraceID(ctx, traceID)
+	}
+	return ctx
+}
+
+// outgoingIDs 将 ctx 中的请求 ID 与链路追踪 ID 写入出站元数据，已显式设置的值保持不变。
+func outgoingIDs(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if id, ok := RequestIDFromContext(ctx); ok && len(md.Get(RequestIDHeader)) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, id)
+	}
+	if id, ok := TraceIDFromContext(ctx); ok && len(md.Get(TraceIDHeader)) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, TraceIDHeader, id)
+	}
+	return ctx
+}
+
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// UnaryServerMetadata 将入站请求的请求 ID 与链路追踪 ID 写入处理函数的 ctx。
+func UnaryServerMetadata() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(incomingIDs(ctx), req)
+	}
+}
+
+// StreamServerMetadata 将入站流的请求 ID 与链路追踪 ID 写入处理函数的 ctx。
+func StreamServerMetadata() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: incomingIDs(ss.Context())})
+	}
+}
+
+// UnaryServerLogging 在一元调用结束后记录方法、对端、耗时与状态码。
+func UnaryServerLogging(l logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, l, "grpc server call", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerLogging 在流结束后记录方法、对端、耗时与状态码。
+func StreamServerLogging(l logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), l, "grpc server stream", info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryServerRecovery 将处理函数中的 panic 转换为不含 panic 内容的 codes.Internal 错误并记录调用栈。
+func UnaryServerRecovery(l logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, l, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecovery 将流处理函数中的 panic 转换为不含 panic 内容的 codes.Internal 错误并记录调用栈。
+func StreamServerRecovery(l logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), l, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, l logger.Logger, method string, r any) error {
+	l.ErrorContext(ctx, "grpc handler panic",
+		logger.Field{Key: "method", Value: method},
+		logger.Field{Key: "panic", Value: r},
+		logger.Field{Key: "stack", Value: string(debug.Stack())},
+	)
+	// panic 值可能包含内部状态，只记录在服务端日志中，不返回给调用方
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryServerDeadline 限制一元调用的处理时间不超过 timeout，调用方给出更早的截止时间时以调用方为准。
+// timeout 为 0 时不做限制。
+func UnaryServerDeadline(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// UnaryClientMetadata 将 ctx 中的请求 ID 与链路追踪 ID 写入出站元数据。
+func UnaryClientMetadata() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingIDs(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientMetadata 将 ctx 中的请求 ID 与链路追踪 ID 写入出站流的元数据。
+func StreamClientMetadata() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingIDs(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryClientDeadline 为未设置截止时间的一元调用设置 timeout 超时，timeout 为 0 时不做处理。
+func UnaryClientDeadline(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryClientLogging 在一元调用结束后记录方法、目标、耗时与状态码。
+func UnaryClientLogging(l logger.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(ctx, l, "grpc client call", method, start, err, logger.Field{Key: "target", Value: cc.Target()})
+		return err
+	}
+}
+
+// StreamClientLogging 记录建立流的结果；流的收发由调用方自行处理。
+func StreamClientLogging(l logger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		logCall(ctx, l, "grpc client stream", method, start, err, logger.Field{Key: "target", Value: cc.Target()})
+		return cs, err
+	}
+}
+
+// logCall 按状态码选择日志等级：成功为 Info，服务端故障为 Error，其余为 Warn。
+// 请求 ID 与链路追踪 ID 随 ctx 传给 Logger 输出。
+func logCall(ctx context.Context, l logger.Logger, msg, method string, start time.Time, err error, extra ...logger.Field) {
+	code := status.Code(err)
+	level := callLevel(code)
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	fields := []logger.Field{
+		{Key: "method", Value: method},
+		{Key: "code", Value: code.String()},
+		{Key: "duration", Value: time.Since(start)},
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, logger.Field{Key: "peer", Value: p.Addr.String()})
+	}
+	fields = append(fields, extra...)
+	if err != nil {
+		fields = append(fields, logger.Field{Key: "error", Value: err})
+	}
+	l.Log(ctx, level, msg, fields...)
+}
+
+func callLevel(code codes.Code) logger.Level {
+	switch code {
+	case codes.OK:
+		return logger.LevelInfo
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		return logger.LevelError
+	default:
+		return logger.LevelWarn
+	}
+}
+
+// contextStream 替换 grpc.ServerStream 的 ctx。
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回替换后的 ctx。
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,202 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+// entry 表示一条记录的日志。
+type entry struct {
+	level  logger.Level
+	msg    string
+	fields map[string]any
+}
+
+// recordLogger 记录所有日志，供断言使用。
+type recordLogger struct {
+	logger.Logger
+	mu      sync.Mutex
+	entries []entry
+}
+
+func newRecordLogger() *recordLogger {
+	return &recordLogger{Logger: logger.Nop()}
+}
+
+func (r *recordLogger) Enabled(_ context.Context, _ logger.Level) bool {
+	return true
+}
+
+func (r *recordLogger) Log(ctx context.Context, level logger.Level, msg string, fields ...logger.Field) {
+	e := entry{level: level, msg: msg, fields: make(map[string]any)}
+	for _, f := range append(logger.ContextFields(ctx), fields...) {
+		e.fields[f.Key] = f.Value
+	}
+	r.mu.Lock()
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+}
+
+func (r *recordLogger) ErrorContext(ctx context.Context, msg string, fields ...logger.Field) {
+	r.Log(ctx, logger.LevelError, msg, fields...)
+}
+
+func (r *recordLogger) find(msg, method string) (entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.msg == msg && e.fields["method"] == method {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// probeServer 是测试拦截器用的 gRPC 服务，依次执行 fn 后返回。
+type probeServer struct {
+	fn func(ctx context.Context) error
+}
+
+var probeDesc = grpc.ServiceDesc{
+	ServiceName: "test.Probe",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Probe",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := new(emptypb.Empty)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, _ any) (any, error) {
+				return &emptypb.Empty{}, srv.(*probeServer).fn(ctx)
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Probe/Probe"}, handler)
+		},
+	}},
+}
+
+func probe(ctx context.Context, conn *grpc.ClientConn) error {
+	return conn.Invoke(ctx, "/test.Probe/Probe", &emptypb.Empty{}, &emptypb.Empty{})
+}
+
+func startProbe(t *testing.T, l logger.Logger, timeout time.Duration, fn func(ctx context.Context) error) *grpc.ClientConn {
+	t.Helper()
+	cfg := testServerConfig()
+	cfg.Timeout = timeout
+	s := NewServer(WithServerConfig(cfg), WithServerLogger(l))
+	s.RegisterService(&probeDesc, &probeServer{fn: fn})
+	require.NoError(t, s.Start(context.Background()))
+	t.Cleanup(func() { _ = s.Stop(context.Background()) })
+
+	conn, err := NewClient(s.Addr().String(),
+		WithClientLogger(l),
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestInterceptorsPropagateIDs(t *testing.T) {
+	l := newRecordLogger()
+	var requestID, traceID string
+	conn := startProbe(t, l, 0, func(ctx context.Context) error {
+		requestID, _ = RequestIDFromContext(ctx)
+		traceID, _ = TraceIDFromContext(ctx)
+		return nil
+	})
+
+	ctx := WithTraceID(WithRequestID(context.Background(), "req-1"), "trace-1")
+	require.NoError(t, probe(ctx, conn))
+	assert.Equal(t, "req-1", requestID)
+	assert.Equal(t, "trace-1", traceID)
+
+	e, ok := l.find("grpc server call", "/test.Probe/Probe")
+	require.True(t, ok)
+	assert.Equal(t, logger.LevelInfo, e.level)
+	assert.Equal(t, codes.OK.String(), e.fields["code"])
+	assert.Equal(t, "req-1", e.fields["request_id"])
+	assert.Equal(t, "trace-1", e.fields["trace_id"])
+	assert.Contains(t, e.fields, "peer")
+	assert.Contains(t, e.fields, "duration")
+
+	e, ok = l.find("grpc client call", "/test.Probe/Probe")
+	require.True(t, ok)
+	assert.Equal(t, "req-1", e.fields["request_id"])
+
+	// 显式设置的出站元数据优先，未携带请求 ID 时服务端生成新的 ID
+	require.NoError(t, probe(metadata.AppendToOutgoingContext(ctx, RequestIDHeader, "req-2"), conn))
+	assert.Equal(t, "req-2", requestID)
+	require.NoError(t, probe(context.Background(), conn))
+	assert.Len(t, requestID, 32)
+	assert.Empty(t, traceID)
+}
+
+func TestInterceptorsRecoverPanic(t *testing.T) {
+	l := newRecordLogger()
+	conn := startProbe(t, l, 0, func(context.Context) error {
+		panic("boom")
+	})
+
+	err := probe(context.Background(), conn)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+
+	e, ok := l.find("grpc handler panic", "/test.Probe/Probe")
+	require.True(t, ok)
+	assert.Equal(t, "boom", e.fields["panic"])
+	e, ok = l.find("grpc server call", "/test.Probe/Probe")
+	require.True(t, ok)
+	assert.Equal(t, logger.LevelError, e.level)
+	assert.Equal(t, codes.Internal.String(), e.fields["code"])
+}
+
+func TestInterceptorsDeadline(t *testing.T) {
+	conn := startProbe(t, logger.Nop(), 50*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	})
+
+	start := time.Now()
+	err := probe(context.Background(), conn)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestUnaryClientDeadline(t *testing.T) {
+	var deadline time.Time
+	var ok bool
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		deadline, ok = ctx.Deadline()
+		return nil
+	}
+
+	require.NoError(t, UnaryClientDeadline(time.Second)(context.Background(), "/m", nil, nil, nil, invoker))
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	// 调用方已设置截止时间时保持不变
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	require.NoError(t, UnaryClientDeadline(time.Second)(ctx, "/m", nil, nil, nil, invoker))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 100*time.Millisecond)
+
+	require.NoError(t, UnaryClientDeadline(0)(context.Background(), "/m", nil, nil, nil, invoker))
+	assert.False(t, ok)
+}
@@ -0,0 +1,121 @@
+// Package rpc 提供基于 gRPC 的服务端、客户端组件，以及接入 registry 服务发现的解析器与负载均衡器。
+package rpc
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/lk2023060901/zeus-go/pkg/registry"
+)
+
+// Scheme 表示服务发现解析器的 scheme，目标地址形如 zeus://game。
+const Scheme = "zeus"
+
+var (
+	errEmptyTarget  = errors.New("rpc: target service name is empty")
+	errNilDiscovery = errors.New("rpc: discovery is nil")
+)
+
+// Target 返回指定服务的 gRPC 目标地址。
+func Target(service string) string {
+	return Scheme + "://" + service
+}
+
+// instanceKey 是实例信息在 resolver.Address.BalancerAttributes 中的键。
+type instanceKey struct{}
+
+// instanceAttr 包装实例信息，使其满足 attributes 对值可比较的要求。
+type instanceAttr struct {
+	registry.Instance
+}
+
+// Equal 比较两个实例信息是否一致。
+func (a instanceAttr) Equal(o any) bool {
+	other, ok := o.(instanceAttr)
+	if !ok {
+		return false
+	}
+	return a.ID == other.ID &&
+		a.Name == other.Name &&
+		a.Address == other.Address &&
+		a.Version == other.Version &&
+		a.Weight == other.Weight &&
+		maps.Equal(a.Metadata, other.Metadata)
+}
+
+// InstanceFromAddress 返回由解析器写入地址的实例信息。
+func InstanceFromAddress(addr resolver.Address) (registry.Instance, bool) {
+	if addr.BalancerAttributes == nil {
+		return registry.Instance{}, false
+	}
+	attr, ok := addr.BalancerAttributes.Value(instanceKey{}).(instanceAttr)
+	return attr.Instance, ok
+}
+
+// Resolver 将 registry.Discovery 中的服务实例提供给 gRPC。
+// 通过 grpc.WithResolvers 注入到单个连接，或调用 resolver.Register 全局注册。
+type Resolver struct {
+	discovery *registry.Discovery
+}
+
+// NewResolver 创建基于服务发现的解析器构造器。
+func NewResolver(d *registry.Discovery) *Resolver {
+	return &Resolver{discovery: d}
+}
+
+// Scheme 返回解析器的 scheme。
+func (r *Resolver) Scheme() string {
+	return Scheme
+}
+
+// Build 订阅目标服务的实例变化，并在每次变化时更新连接的地址列表。
+func (r *Resolver) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	if r.discovery == nil {
+		return nil, errNilDiscovery
+	}
+	name := target.URL.Host
+	if name == "" {
+		name = target.Endpoint()
+	}
+	if name == "" {
+		return nil, errEmptyTarget
+	}
+
+	unsubscribe, err := r.discovery.Subscribe(context.Background(), name, func(insts []registry.Instance) {
+		if err := cc.UpdateState(resolver.State{Addresses: addresses(insts)}); err != nil {
+			cc.ReportError(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &discoveryResolver{unsubscribe: unsubscribe}, nil
+}
+
+// addresses 将实例列表转换为 gRPC 地址列表。
+func addresses(insts []registry.Instance) []resolver.Address {
+	addrs := make([]resolver.Address, 0, len(insts))
+	for _, inst := range insts {
+		addrs = append(addrs, resolver.Address{
+			Addr:               inst.Address,
+			BalancerAttributes: attributes.New(instanceKey{}, instanceAttr{Instance: inst}),
+		})
+	}
+	return addrs
+}
+
+type discoveryResolver struct {
+	unsubscribe func()
+}
+
+// ResolveNow 无需处理，实例变化由服务发现主动推送。
+func (r *discoveryResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+// Close 取消订阅。
+func (r *discoveryResolver) Close() {
+	r.unsubscribe()
+}
@@ -0,0 +1,320 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/lk2023060901/zeus-go/pkg/app"
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+	"github.com/lk2023060901/zeus-go/pkg/service"
+)
+
+// ServerID 表示 gRPC 服务的默认 ID，同时也是其配置段名称。
+const ServerID = "grpc"
+
+var (
+	errEmptyAddr     = errors.New("rpc: server addr is empty")
+	errServerRunning = errors.New("rpc: cannot register service while server is running")
+)
+
+// ServerConfig gRPC 服务配置。
+type ServerConfig struct {
+	// Addr 表示监听地址。
+	Addr string `yaml:"addr"`
+	// ShutdownTimeout 表示 Stop 时等待进行中的 RPC 完成的最长时间，超时后强制关闭。
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// Timeout 表示一元调用的最长处理时间，0 表示不限制。
+	Timeout time.Duration `yaml:"timeout"`
+	// Health 表示是否注册 grpc.health.v1 健康检查服务。
+	Health bool `yaml:"health"`
+	// Reflection 表示是否注册反射服务，便于 grpcurl 等工具调试。
+	Reflection bool `yaml:"reflection"`
+}
+
+// DefaultServerConfig 返回默认配置。
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Addr:            ":9000",
+		ShutdownTimeout: 10 * time.Second,
+		Health:          true,
+	}
+}
+
+// ServerOption gRPC 服务选项。
+type ServerOption func(*Server)
+
+// WithServerID 设置服务 ID 与配置段名称，同一应用运行多个 gRPC 服务时使用。
+func WithServerID(id string) ServerOption {
+	return func(s *Server) {
+		s.id = id
+	}
+}
+
+// WithServerConfig 设置服务配置，配置文件中的同名配置段会在 Init 前覆盖该配置。
+func WithServerConfig(cfg ServerConfig) ServerOption {
+	return func(s *Server) {
+		s.cfg = cfg
+	}
+}
+
+// WithServerRequires 设置服务依赖的组件 ID，例如业务服务使用的存储。
+func WithServerRequires(ids ...string) ServerOption {
+	return func(s *Server) {
+		s.requires = append(s.requires, ids...)
+	}
+}
+
+// WithGRPCOptions 追加创建 grpc.Server 时使用的选项，例如拦截器、消息大小限制。
+// 追加的拦截器排在 ServerInterceptors 标准拦截器之后执行。
+func WithGRPCOptions(opts ...grpc.ServerOption) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, opts...)
+	}
+}
+
+// WithServerLogger 设置日志记录器。
+func WithServerLogger(l logger.Logger) ServerOption {
+	return func(s *Server) {
+		s.logger = l
+	}
+}
+
+// Server 是由应用管理生命周期的 gRPC 服务：Start 在后台监听并提供服务，
+// Drain 将健康状态置为 NOT_SERVING 并等待进行中的 RPC 完成，
+// Stop 在 ShutdownTimeout 内优雅关闭，超时后强制关闭。
+// 所有调用都经过 ServerInterceptors 提供的日志、panic 恢复、元数据透传与超时控制。
+//
+// Server 实现了 grpc.ServiceRegistrar，生成的注册函数可直接使用：
+//
+//	srv := rpc.NewServer()
+//	protos.RegisterChatServiceServer(srv, &chatServer{})
+//	_ = application.RegisterService(srv)
+//
+// 每次 Start 都会创建新的 grpc.Server，因此服务可被监管重启。
+type Server struct {
+	id       string
+	cfg      ServerConfig
+	requires []string
+	grpcOpts []grpc.ServerOption
+	logger   logger.Logger
+
+	mu       sync.Mutex
+	services []registration
+	server   *grpc.Server
+	health   *health.Server
+	addr     net.Addr
+	serving  *conc.Future[struct{}]
+	failures chan error
+}
+
+// registration 表示一个待注册的 gRPC 服务实现。
+type registration struct {
+	desc *grpc.ServiceDesc
+	impl any
+}
+
+// NewServer 创建 gRPC 服务。
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{
+		id:     ServerID,
+		cfg:    DefaultServerConfig(),
+		logger: logger.Nop(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ID 返回服务 ID。
+func (s *Server) ID() string {
+	return s.id
+}
+
+// Requires 返回依赖的组件 ID 列表。
+func (s *Server) Requires() []string {
+	return s.requires
+}
+
+// Configure 从与服务 ID 同名的配置段读取配置。
+func (s *Server) Configure(dec app.ConfigDecoder) error {
+	return dec.Decode(&s.cfg)
+}
+
+// RegisterService 登记 gRPC 服务实现，在 Start 时注册到新创建的 grpc.Server。
+// 运行中调用时 panic，与 grpc.Server 的行为一致。
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server != nil {
+		panic(errServerRunning)
+	}
+	s.services = append(s.services, registration{desc: desc, impl: impl})
+}
+
+// Init 校验配置。
+func (s *Server) Init(_ context.Context) error {
+	if s.cfg.Addr == "" {
+		return errEmptyAddr
+	}
+	return nil
+}
+
+// Start 监听配置的地址并在后台提供服务，监听失败时返回错误。
+func (s *Server) Start(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server != nil {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	opts := append(ServerInterceptors(s.logger, s.cfg.Timeout), s.grpcOpts...)
+	srv := grpc.NewServer(opts...)
+	for _, r := range s.services {
+		srv.RegisterService(r.desc, r.impl)
+	}
+	var hs *health.Server
+	if s.cfg.Health {
+		hs = health.NewServer()
+		healthpb.RegisterHealthServer(srv, hs)
+		for _, r := range s.services {
+			hs.SetServingStatus(r.desc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+		}
+	}
+	if s.cfg.Reflection {
+		reflection.Register(srv)
+	}
+
+	failures := make(chan error, 1)
+	s.server, s.health, s.addr, s.failures = srv, hs, lis.Addr(), failures
+	s.serving = conc.Go(func() (struct{}, error) {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("grpc server stopped",
+				logger.Field{Key: "service", Value: s.id},
+				logger.Field{Key: "addr", Value: lis.Addr().String()},
+				logger.Field{Key: "error", Value: err},
+			)
+			failures <- err
+			return struct{}{}, err
+		}
+		return struct{}{}, nil
+	})
+	s.logger.Info("grpc server started",
+		logger.Field{Key: "service", Value: s.id},
+		logger.Field{Key: "addr", Value: lis.Addr().String()},
+	)
+	return nil
+}
+
+// Drain 将健康状态置为 NOT_SERVING、拒绝新的连接与 RPC，并等待进行中的 RPC 完成；
+// ctx 结束时返回 ctx 的错误，剩余 RPC 交由 Stop 处理。
+func (s *Server) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	srv, hs := s.server, s.health
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+
+	if hs != nil {
+		hs.Shutdown()
+	}
+	done := gracefulStop(srv)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop 优雅关闭服务，等待时间不超过 ShutdownTimeout 与 ctx 的较早者；
+// 超时后强制关闭并返回包装了 ctx 错误的 error。
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	srv, hs, serving := s.server, s.health, s.serving
+	s.server, s.health, s.addr, s.serving = nil, nil, nil, nil
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+
+	if hs != nil {
+		hs.Shutdown()
+	}
+	if s.cfg.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+		defer cancel()
+	}
+	done := gracefulStop(srv)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Warn("grpc graceful stop timed out, forcing stop",
+			logger.Field{Key: "service", Value: s.id},
+		)
+		// 强制关闭会取消处理函数的 ctx 并断开连接，但 srv.Stop 与 Serve 仍会等待处理函数返回，
+		// 忽略取消的处理函数会使其一直阻塞，因此在后台关闭且不再等待
+		conc.Go(func() (struct{}, error) {
+			srv.Stop()
+			return struct{}{}, nil
+		})
+		return fmt.Errorf("rpc: graceful stop: %w", ctx.Err())
+	}
+	serving.Await()
+	return nil
+}
+
+// gracefulStop 在后台优雅关闭 srv，返回的通道在关闭完成后关闭。
+func gracefulStop(srv *grpc.Server) <-chan struct{} {
+	done := make(chan struct{})
+	conc.Go(func() (struct{}, error) {
+		srv.GracefulStop()
+		close(done)
+		return struct{}{}, nil
+	})
+	return done
+}
+
+// Failures 返回运行期故障通道，Serve 异常退出时写入错误。
+func (s *Server) Failures() <-chan error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failures
+}
+
+// Addr 返回实际监听地址，未启动时返回 nil。
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// Health 返回健康检查服务，用于按业务状态调整服务状态；未启用或未启动时返回 nil。
+func (s *Server) Health() *health.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+var (
+	_ grpc.ServiceRegistrar = (*Server)(nil)
+	_ service.Supervised    = (*Server)(nil)
+	_ service.Drainer       = (*Server)(nil)
+)
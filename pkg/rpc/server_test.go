@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// echoServer 是测试用的 gRPC 服务，block 非空时处理请求前等待其关闭。
+type echoServer struct {
+	block chan struct{}
+}
+
+func (e *echoServer) echo(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if e.block != nil {
+		<-e.block
+	}
+	return &emptypb.Empty{}, nil
+}
+
+var echoDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			in := new(emptypb.Empty)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			return srv.(*echoServer).echo(ctx, in)
+		},
+	}},
+}
+
+func testServerConfig() ServerConfig {
+	cfg := DefaultServerConfig()
+	cfg.Addr = "127.0.0.1:0"
+	cfg.Reflection = true
+	return cfg
+}
+
+func dial(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(s.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func echo(ctx context.Context, conn *grpc.ClientConn) error {
+	return conn.Invoke(ctx, "/test.Echo/Echo", &emptypb.Empty{}, &emptypb.Empty{})
+}
+
+func TestServerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer(WithServerConfig(testServerConfig()), WithServerRequires("storage"))
+	s.RegisterService(&echoDesc, &echoServer{})
+
+	assert.Equal(t, ServerID, s.ID())
+	assert.Equal(t, []string{"storage"}, s.Requires())
+	assert.Nil(t, s.Addr())
+	require.NoError(t, s.Init(ctx))
+	require.NoError(t, s.Start(ctx))
+	require.NoError(t, s.Start(ctx))
+	require.NotNil(t, s.Addr())
+	assert.NotNil(t, s.Failures())
+	assert.Panics(t, func() { s.RegisterService(&echoDesc, &echoServer{}) })
+
+	conn := dial(t, s)
+	require.NoError(t, echo(ctx, conn))
+
+	hc := healthpb.NewHealthClient(conn)
+	for _, name := range []string{"", "test.Echo"} {
+		resp, err := hc.Check(ctx, &healthpb.HealthCheckRequest{Service: name})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status, name)
+	}
+
+	// 排空后健康状态变为 NOT_SERVING
+	hs := s.Health()
+	require.NoError(t, s.Drain(ctx))
+	resp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: "test.Echo"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	require.NoError(t, s.Stop(ctx))
+	assert.Nil(t, s.Addr())
+	require.NoError(t, s.Stop(ctx))
+
+	// 停止后可再次启动
+	require.NoError(t, s.Start(ctx))
+	require.NoError(t, echo(ctx, dial(t, s)))
+	require.NoError(t, s.Stop(ctx))
+}
+
+func TestServerInit(t *testing.T) {
+	s := NewServer(WithServerID("grpc-internal"), WithServerConfig(ServerConfig{}))
+	assert.Equal(t, "grpc-internal", s.ID())
+	assert.ErrorIs(t, s.Init(context.Background()), errEmptyAddr)
+}
+
+func TestServerStopTimeout(t *testing.T) {
+	ctx := context.Background()
+	cfg := testServerConfig()
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+	block := make(chan struct{})
+	defer close(block)
+
+	s := NewServer(WithServerConfig(cfg))
+	s.RegisterService(&echoDesc, &echoServer{block: block})
+	require.NoError(t, s.Start(ctx))
+
+	conn := dial(t, s)
+	result := make(chan error, 1)
+	go func() {
+		result <- echo(ctx, conn)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// 排空在 ctx 结束时返回，进行中的 RPC 交由 Stop 强制结束
+	drainCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Drain(drainCtx), context.DeadlineExceeded)
+
+	start := time.Now()
+	assert.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Error(t, <-result)
+}
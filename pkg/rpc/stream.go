@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/lk2023060901/zeus-go/pkg/session"
+)
+
+// streamTransport 将服务端双向流适配为会话传输层。
+type streamTransport[T any] struct {
+	stream grpc.BidiStreamingServer[T, T]
+}
+
+// NewStreamTransport 将生成代码中的服务端双向流适配为会话传输层。
+// gRPC 流无法由服务端主动关闭，会话关闭后 Serve 返回，处理函数随之返回即可结束流。
+func NewStreamTransport[T any](stream grpc.BidiStreamingServer[T, T]) session.Transport[*T] {
+	return streamTransport[T]{stream: stream}
+}
+
+func (t streamTransport[T]) Send(msg *T) error {
+	return t.stream.Send(msg)
+}
+
+func (t streamTransport[T]) Recv() (*T, error) {
+	return t.stream.Recv()
+}
+
+func (t streamTransport[T]) Close() error {
+	return nil
+}
+
+func (t streamTransport[T]) RemoteAddr() string {
+	if p, ok := peer.FromContext(t.stream.Context()); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// ServeStream 为双向流创建会话并处理收到的消息，直到流结束或会话关闭，
+// 会话因空闲、发送队列阻塞或管理器关闭而结束时返回对应的 gRPC 状态码。
+// 在生成代码的流处理函数中直接返回其结果：
+//
+//	func (s *chatServer) Chat(stream protos.ChatService_ChatServer) error {
+//		return rpc.ServeStream(s.sessions, stream, s.handle)
+//	}
+func ServeStream[T any](m *session.Manager[*T], stream grpc.BidiStreamingServer[T, T], handler session.Handler[*T]) error {
+	s, err := m.Open(stream.Context(), "", NewStreamTransport(stream))
+	if err != nil {
+		return sessionStatus(err)
+	}
+	return sessionStatus(s.Serve(handler))
+}
+
+// sessionStatus 将会话关闭原因转换为 gRPC 状态，其余错误原样返回。
+func sessionStatus(err error) error {
+	switch {
+	case errors.Is(err, session.ErrIdleTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, session.ErrSendQueueFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, session.ErrManagerClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return err
+	}
+}
@@ -6,34 +6,37 @@ import "time"
 // Config 调度器配置
 type Config struct {
 	// Timezone 时区，默认 Asia/Shanghai
-	Timezone string `mapstructure:"timezone"`
+	Timezone string `mapstructure:"timezone" yaml:"timezone"`
 
 	// WithSeconds 是否启用秒级精度（6位表达式），默认 false
-	WithSeconds bool `mapstructure:"with_seconds"`
+	WithSeconds bool `mapstructure:"with_seconds" yaml:"with_seconds"`
 
 	// JobTimeout 任务执行超时时间，0 表示不限制
-	JobTimeout time.Duration `mapstructure:"job_timeout"`
+	JobTimeout time.Duration `mapstructure:"job_timeout" yaml:"job_timeout"`
 
 	// SkipIfStillRunning 如果上次执行未完成则跳过，默认 true
-	SkipIfStillRunning bool `mapstructure:"skip_if_still_running"`
+	SkipIfStillRunning bool `mapstructure:"skip_if_still_running" yaml:"skip_if_still_running"`
 
 	// Middleware 中间件配置
-	Middleware MiddlewareConfig `mapstructure:"middleware"`
+	Middleware MiddlewareConfig `mapstructure:"middleware" yaml:"middleware"`
 
 	// DefaultJobOptions 默认任务选项（可被单个任务覆盖）
-	DefaultJobOptions JobOptions `mapstructure:"default_job_options"`
+	DefaultJobOptions JobOptions `mapstructure:"default_job_options" yaml:"default_job_options"`
+
+	// Jobs 按任务名称覆盖的任务选项，优先于 DefaultJobOptions，添加任务时指定的选项优先于该配置
+	Jobs map[string]JobOptions `mapstructure:"jobs" yaml:"jobs"`
 }
 
 // MiddlewareConfig 中间件配置
 type MiddlewareConfig struct {
 	// Logging 启用日志记录
-	Logging bool `mapstructure:"logging"`
+	Logging bool `mapstructure:"logging" yaml:"logging"`
 
 	// Recovery 启用 panic 恢复
-	Recovery bool `mapstructure:"recovery"`
+	Recovery bool `mapstructure:"recovery" yaml:"recovery"`
 
 	// Metrics 启用 Prometheus 指标
-	Metrics bool `mapstructure:"metrics"`
+	Metrics bool `mapstructure:"metrics" yaml:"metrics"`
 }
 
 // BackoffStrategy 退避策略
@@ -51,19 +54,19 @@ const (
 // JobOptions 任务选项
 type JobOptions struct {
 	// MaxRetries 失败重试次数，0 表示不重试
-	MaxRetries int `mapstructure:"max_retries"`
+	MaxRetries int `mapstructure:"max_retries" yaml:"max_retries"`
 
 	// BackoffStrategy 退避策略
-	BackoffStrategy BackoffStrategy `mapstructure:"backoff_strategy"`
+	BackoffStrategy BackoffStrategy `mapstructure:"backoff_strategy" yaml:"backoff_strategy"`
 
 	// InitialBackoff 初始退避时间
-	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" yaml:"initial_backoff"`
 
 	// MaxBackoff 最大退避时间
-	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff" yaml:"max_backoff"`
 
 	// BackoffMultiplier 退避乘数（仅 exponential 有效）
-	BackoffMultiplier float64 `mapstructure:"backoff_multiplier"`
+	BackoffMultiplier float64 `mapstructure:"backoff_multiplier" yaml:"backoff_multiplier"`
 }
 
 // DefaultConfig 返回默认配置
@@ -2,6 +2,7 @@
 package scheduler
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -60,10 +61,14 @@ type jobEntry struct {
 	job       Job
 	fn        JobFunc
 	options   JobOptions
-	runCount  int64
-	failCount int64
-	running   atomic.Bool
-	lastRun   time.Time
+	optionsMu sync.RWMutex
+	custom    bool
+	// configured 表示选项来自 Config.Jobs
+	configured bool
+	runCount   int64
+	failCount  int64
+	running    atomic.Bool
+	lastRun    time.Time
 }
 
 // newJobEntry 创建任务条目
@@ -127,9 +132,18 @@ func (e *jobEntry) LastRun() time.Time {
 
 // Options 返回任务选项
 func (e *jobEntry) Options() JobOptions {
+	e.optionsMu.RLock()
+	defer e.optionsMu.RUnlock()
 	return e.options
 }
 
+// SetOptions 更新任务选项，下次执行时生效
+func (e *jobEntry) SetOptions(opts JobOptions) {
+	e.optionsMu.Lock()
+	e.options = opts
+	e.optionsMu.Unlock()
+}
+
 // SetID 设置任务 ID
 func (e *jobEntry) SetID(id JobID) {
 	e.id = id
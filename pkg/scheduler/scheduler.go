@@ -4,7 +4,9 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"maps"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -23,6 +25,13 @@ type Scheduler struct {
 	jobsMu  sync.RWMutex
 	running bool
 	runMu   sync.RWMutex
+
+	// drainMu 保护排空状态与 RunNow 提交到协程池的任务计数
+	drainMu  sync.Mutex
+	draining bool
+	inflight int
+	// idle 在 inflight 归零时关闭，由等待中的 Drain 创建
+	idle chan struct{}
 }
 
 // New 创建调度器
@@ -86,7 +95,7 @@ func WithPool(pool *conc.Pool[any]) SchedulerOption {
 
 // AddJob 添加任务
 func (s *Scheduler) AddJob(name, spec string, job Job, opts ...JobOption) (JobID, error) {
-	entry := newJobEntry(name, spec, s.config.DefaultJobOptions)
+	entry := s.newEntry(name, spec, len(opts) > 0)
 	entry.job = job
 
 	// 应用任务选项
@@ -99,7 +108,7 @@ func (s *Scheduler) AddJob(name, spec string, job Job, opts ...JobOption) (JobID
 
 // AddFunc 添加函数任务
 func (s *Scheduler) AddFunc(name, spec string, fn JobFunc, opts ...JobOption) (JobID, error) {
-	entry := newJobEntry(name, spec, s.config.DefaultJobOptions)
+	entry := s.newEntry(name, spec, len(opts) > 0)
 	entry.fn = fn
 
 	// 应用任务选项
@@ -110,6 +119,20 @@ func (s *Scheduler) AddFunc(name, spec string, fn JobFunc, opts ...JobOption) (J
 	return s.addEntry(spec, entry)
 }
 
+// newEntry 创建使用默认或按名称配置的任务选项的任务条目，custom 表示添加时指定了任务选项
+func (s *Scheduler) newEntry(name, spec string, custom bool) *jobEntry {
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+	if opts, ok := s.config.Jobs[name]; ok && !custom {
+		entry := newJobEntry(name, spec, opts)
+		entry.configured = true
+		return entry
+	}
+	entry := newJobEntry(name, spec, s.config.DefaultJobOptions)
+	entry.custom = custom
+	return entry
+}
+
 // addEntry 添加任务条目到调度器
 func (s *Scheduler) addEntry(spec string, entry *jobEntry) (JobID, error) {
 	// 包装任务执行
@@ -209,7 +232,7 @@ func (s *Scheduler) wrapJob(entry *jobEntry) cron.Job {
 		}
 
 		// 执行任务（带重试）
-		executor := NewRetryExecutor(entry.options)
+		executor := NewRetryExecutor(entry.Options())
 		jobErr = executor.ExecuteWithCallback(
 			func() error {
 				if entry.job != nil {
@@ -233,6 +256,99 @@ func (s *Scheduler) wrapJob(entry *jobEntry) cron.Job {
 	})
 }
 
+// Config 返回当前配置的副本
+func (s *Scheduler) Config() Config {
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+	cfg := *s.config
+	cfg.Jobs = maps.Clone(s.config.Jobs)
+	return cfg
+}
+
+// DefaultJobOptions 返回当前的默认任务选项
+func (s *Scheduler) DefaultJobOptions() JobOptions {
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+	return s.config.DefaultJobOptions
+}
+
+// SetDefaultJobOptions 更新默认任务选项（用于配置热更新）
+// 新选项对之后添加的任务生效，并同步到添加时未指定任务选项的已有任务
+func (s *Scheduler) SetDefaultJobOptions(opts JobOptions) {
+	s.jobsMu.Lock()
+	s.config.DefaultJobOptions = opts
+	entries := make([]*jobEntry, 0, len(s.jobs))
+	for _, entry := range s.jobs {
+		if !entry.custom && !entry.configured {
+			entries = append(entries, entry)
+		}
+	}
+	s.jobsMu.Unlock()
+
+	for _, entry := range entries {
+		entry.SetOptions(opts)
+	}
+
+	s.logger.Info("default job options updated", fields(
+		"jobs", len(entries),
+		"max_retries", opts.MaxRetries,
+		"backoff_strategy", opts.BackoffStrategy,
+	)...)
+}
+
+// UpdateJobOptions 更新指定任务的选项，下次执行时生效
+func (s *Scheduler) UpdateJobOptions(id JobID, opts JobOptions) error {
+	s.jobsMu.Lock()
+	entry, exists := s.jobs[id]
+	if exists {
+		entry.custom = true
+		entry.configured = false
+	}
+	s.jobsMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("job %d not found", id)
+	}
+
+	entry.SetOptions(opts)
+	return nil
+}
+
+// ApplyConfig 应用配置中可热更新的任务选项：更新默认任务选项，并按 Jobs 更新同名任务的选项，
+// 从 Jobs 中移除的任务恢复为默认任务选项。通过 AddJob 选项或 UpdateJobOptions 指定选项的任务不受影响。
+// 时区、秒级精度等其他配置需重新创建调度器后生效。
+func (s *Scheduler) ApplyConfig(cfg *Config) {
+	s.SetDefaultJobOptions(cfg.DefaultJobOptions)
+
+	type update struct {
+		entry *jobEntry
+		opts  JobOptions
+	}
+	var updates []update
+	s.jobsMu.Lock()
+	s.config.Jobs = maps.Clone(cfg.Jobs)
+	for _, entry := range s.jobs {
+		if entry.custom {
+			continue
+		}
+		if opts, ok := cfg.Jobs[entry.name]; ok {
+			entry.configured = true
+			updates = append(updates, update{entry: entry, opts: opts})
+		} else if entry.configured {
+			entry.configured = false
+			updates = append(updates, update{entry: entry, opts: cfg.DefaultJobOptions})
+		}
+	}
+	s.jobsMu.Unlock()
+
+	for _, u := range updates {
+		u.entry.SetOptions(u.opts)
+	}
+	if len(updates) > 0 {
+		s.logger.Info("job options updated", fields("jobs", len(updates))...)
+	}
+}
+
 // RemoveJob 移除任务
 func (s *Scheduler) RemoveJob(id JobID) {
 	s.cron.Remove(id)
@@ -261,6 +377,9 @@ func (s *Scheduler) Start() {
 
 	s.cron.Start()
 	s.running = true
+	s.drainMu.Lock()
+	s.draining = false
+	s.drainMu.Unlock()
 
 	s.logger.Info("scheduler started")
 }
@@ -282,6 +401,53 @@ func (s *Scheduler) Stop() context.Context {
 	return ctx
 }
 
+// Drain 停止调度新的任务执行并拒绝 RunNow，等待执行中的任务完成或 ctx 结束
+// 重新调用 Start 后恢复调度
+func (s *Scheduler) Drain(ctx context.Context) error {
+	s.drainMu.Lock()
+	s.draining = true
+	idle := s.idleLocked()
+	s.drainMu.Unlock()
+	s.Stop()
+
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-idle:
+		s.logger.Info("scheduler drained")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// idleLocked 返回在 RunNow 提交的任务全部结束时关闭的通道，调用方需持有 drainMu
+func (s *Scheduler) idleLocked() <-chan struct{} {
+	if s.inflight == 0 {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	if s.idle == nil {
+		s.idle = make(chan struct{})
+	}
+	return s.idle
+}
+
+// doneInflight 结束一个 RunNow 提交的任务，计数归零时唤醒等待中的 Drain
+func (s *Scheduler) doneInflight() {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	s.inflight--
+	if s.inflight == 0 && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+}
+
 // IsRunning 返回调度器是否正在运行
 func (s *Scheduler) IsRunning() bool {
 	s.runMu.RLock()
@@ -311,7 +477,7 @@ func (s *Scheduler) GetJob(id JobID) (*JobInfo, bool) {
 		RunCount:  entry.runCount,
 		FailCount: entry.failCount,
 		Running:   entry.IsRunning(),
-		Options:   entry.options,
+		Options:   entry.Options(),
 	}, true
 }
 
@@ -332,7 +498,7 @@ func (s *Scheduler) ListJobs() []*JobInfo {
 			RunCount:  entry.runCount,
 			FailCount: entry.failCount,
 			Running:   entry.IsRunning(),
-			Options:   entry.options,
+			Options:   entry.Options(),
 		})
 	}
 
@@ -348,12 +514,32 @@ func (s *Scheduler) RunNow(id JobID) error {
 	if !exists {
 		return fmt.Errorf("job %d not found", id)
 	}
+	// 排空检查与计数在同一把锁内完成，Drain 设置排空后不会再有新任务计入
+	s.drainMu.Lock()
+	if s.draining {
+		s.drainMu.Unlock()
+		return fmt.Errorf("scheduler is draining, job %d rejected", id)
+	}
+	s.inflight++
+	s.drainMu.Unlock()
 
 	// 使用协程池执行
-	s.pool.Submit(func() (any, error) {
+	var ran atomic.Bool
+	future := s.pool.Submit(func() (any, error) {
+		ran.Store(true)
+		defer s.doneInflight()
 		s.wrapJob(entry).Run()
 		return nil, nil
 	})
+	// 提交失败时 Submit 返回已结束且带错误的 Future，任务不会执行
+	select {
+	case <-future.Inner():
+		if err := future.Err(); err != nil && !ran.Load() {
+			s.doneInflight()
+			return fmt.Errorf("submit job %d: %w", id, err)
+		}
+	default:
+	}
 
 	return nil
 }
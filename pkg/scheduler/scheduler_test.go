@@ -2,6 +2,7 @@
 package scheduler
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"sync/atomic"
@@ -326,6 +327,71 @@ func TestRunNow(t *testing.T) {
 	}
 }
 
+// TestDrain 测试排空时等待执行中的任务并拒绝新任务
+func TestDrain(t *testing.T) {
+	s, err := New(nil)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	defer s.Release()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	id, err := s.AddFunc("slow-job", "0 0 1 1 *", func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	s.Start()
+
+	if err := s.RunNow(id); err != nil {
+		t.Fatalf("RunNow() error = %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Drain(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Drain() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if s.IsRunning() {
+		t.Error("Scheduler should not be running after Drain()")
+	}
+	if err := s.RunNow(id); err == nil {
+		t.Error("RunNow() should be rejected while draining")
+	}
+
+	close(release)
+	if err := s.Drain(context.Background()); err != nil {
+		t.Errorf("Drain() error = %v", err)
+	}
+}
+
+// TestRunNowSubmitFailed 测试协程池拒绝任务时 RunNow 返回错误且不阻塞 Drain
+func TestRunNowSubmitFailed(t *testing.T) {
+	s, err := New(nil)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	id, err := s.AddFunc("job", "0 0 1 1 *", func() error { return nil })
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	s.Release()
+
+	if err := s.RunNow(id); err == nil {
+		t.Error("RunNow() should return error when the pool is released")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Drain(ctx); err != nil {
+		t.Errorf("Drain() error = %v", err)
+	}
+}
+
 // TestRunNowNotFound 测试立即执行不存在的任务
 func TestRunNowNotFound(t *testing.T) {
 	s, err := New(nil)
@@ -443,6 +509,109 @@ func TestJobOptions(t *testing.T) {
 	}
 }
 
+// TestSetDefaultJobOptions 测试热更新默认任务选项
+func TestSetDefaultJobOptions(t *testing.T) {
+	s, err := New(nil)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	defer s.Release()
+
+	defaultID, err := s.AddFunc("default-job", "* * * * *", func() error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	customID, err := s.AddFunc("custom-job", "* * * * *", func() error {
+		return nil
+	}, WithMaxRetries(7))
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+
+	updated := JobOptions{
+		MaxRetries:      1,
+		BackoffStrategy: BackoffFixed,
+		InitialBackoff:  time.Second,
+	}
+	s.SetDefaultJobOptions(updated)
+
+	if got := s.DefaultJobOptions(); got != updated {
+		t.Errorf("DefaultJobOptions() = %+v, want %+v", got, updated)
+	}
+	if job, _ := s.GetJob(defaultID); job.Options != updated {
+		t.Errorf("default job options = %+v, want %+v", job.Options, updated)
+	}
+	if job, _ := s.GetJob(customID); job.Options.MaxRetries != 7 {
+		t.Errorf("custom job MaxRetries = %d, want 7", job.Options.MaxRetries)
+	}
+
+	if err := s.UpdateJobOptions(customID, updated); err != nil {
+		t.Fatalf("UpdateJobOptions() error = %v", err)
+	}
+	if job, _ := s.GetJob(customID); job.Options != updated {
+		t.Errorf("custom job options = %+v, want %+v", job.Options, updated)
+	}
+	if err := s.UpdateJobOptions(JobID(9999), updated); err == nil {
+		t.Error("UpdateJobOptions() should fail for unknown job")
+	}
+}
+
+// TestApplyConfig 测试按配置热更新默认与按名称配置的任务选项
+func TestApplyConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Jobs = map[string]JobOptions{"settle": {MaxRetries: 5, BackoffStrategy: BackoffFixed}}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	defer s.Release()
+
+	noop := func() error { return nil }
+	settleID, err := s.AddFunc("settle", "* * * * *", noop)
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	otherID, err := s.AddFunc("other", "* * * * *", noop)
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	customID, err := s.AddFunc("settle", "* * * * *", noop, WithMaxRetries(7))
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	if job, _ := s.GetJob(settleID); job.Options.MaxRetries != 5 {
+		t.Errorf("configured job MaxRetries = %d, want 5", job.Options.MaxRetries)
+	}
+
+	defaults := JobOptions{MaxRetries: 1, BackoffStrategy: BackoffNone}
+	other := JobOptions{MaxRetries: 9, BackoffStrategy: BackoffFixed}
+	s.ApplyConfig(&Config{
+		DefaultJobOptions: defaults,
+		Jobs:              map[string]JobOptions{"other": other},
+	})
+	// settle 从 Jobs 中移除后恢复默认选项，other 使用新配置，添加时指定选项的任务不变
+	if job, _ := s.GetJob(settleID); job.Options != defaults {
+		t.Errorf("settle options = %+v, want %+v", job.Options, defaults)
+	}
+	if job, _ := s.GetJob(otherID); job.Options != other {
+		t.Errorf("other options = %+v, want %+v", job.Options, other)
+	}
+	if job, _ := s.GetJob(customID); job.Options.MaxRetries != 7 {
+		t.Errorf("custom job MaxRetries = %d, want 7", job.Options.MaxRetries)
+	}
+
+	// 默认选项变化不影响按名称配置的任务
+	s.SetDefaultJobOptions(JobOptions{MaxRetries: 2})
+	if job, _ := s.GetJob(otherID); job.Options != other {
+		t.Errorf("other options = %+v, want %+v", job.Options, other)
+	}
+	if job, _ := s.GetJob(settleID); job.Options.MaxRetries != 2 {
+		t.Errorf("settle MaxRetries = %d, want 2", job.Options.MaxRetries)
+	}
+}
+
 // TestWithNoRetry 测试禁用重试
 func TestWithNoRetry(t *testing.T) {
 	s, err := New(nil)
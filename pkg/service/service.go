@@ -3,6 +3,8 @@ package service
 import "context"
 
 // Service 定义可插拔服务的生命周期。
+// 各生命周期方法应在 ctx 结束时尽快返回。超时后应用不再等待该调用，
+// 但在对同一组件执行下一次生命周期调用（如 Drain 之后的 Stop）前，会在其超时内等待它返回。
 type Service interface {
 	// ID 返回服务的唯一标识。
 	ID() string
@@ -15,3 +17,18 @@ type Service interface {
 	// Stop 停止服务并释放资源。
 	Stop(ctx context.Context) error
 }
+
+// Supervised 定义可被应用监管的服务，启动后通过故障通道上报运行期故障。
+type Supervised interface {
+	Service
+	// Failures 返回运行期故障通道，服务在运行中出现无法自愈的故障时写入错误。
+	// 应用在每次 Start 成功后都会重新获取该通道。
+	Failures() <-chan error
+}
+
+// Drainer 定义支持优雅排空的服务（或模块），应用在关闭流程中、Stop 之前调用 Drain。
+type Drainer interface {
+	// Drain 停止接收新的工作（连接、任务、RPC），并在 ctx 结束前完成进行中的工作。
+	// ctx 结束时应尽快返回，剩余工作交由随后的 Stop 处理。
+	Drain(ctx context.Context) error
+}
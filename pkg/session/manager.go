@@ -0,0 +1,309 @@
+package session
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+// Hooks 定义会话钩子，未设置的字段会被忽略。
+type Hooks[T any] struct {
+	// OnOpen 在会话登记后、开始收发消息前执行。
+	OnOpen func(s *Session[T])
+	// OnClose 在会话关闭并注销后执行，err 为关闭原因。
+	OnClose func(s *Session[T], err error)
+	// OnHeartbeat 按 HeartbeatInterval 在会话的写协程中执行，通常用 TrySend 发送心跳消息；
+	// 在其中调用会阻塞的 Send 会推迟队列中消息的发送。
+	OnHeartbeat func(s *Session[T])
+}
+
+// Manager 登记所有活跃会话，支持按 ID、分组或全体推送消息，可被多个协程并发使用。
+type Manager[T any] struct {
+	opts options
+
+	mu       sync.RWMutex
+	sessions map[string]*Session[T]
+	groups   map[string]map[string]*Session[T]
+	hooks    []Hooks[T]
+	closed   bool
+}
+
+// NewManager 创建会话管理器。
+func NewManager[T any](opts ...Option) *Manager[T] {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Manager[T]{
+		opts:     o,
+		sessions: make(map[string]*Session[T]),
+		groups:   make(map[string]map[string]*Session[T]),
+	}
+}
+
+// AddHooks 注册一组会话钩子，多组钩子按注册顺序执行。
+func (m *Manager[T]) AddHooks(h Hooks[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, h)
+}
+
+// Open 以 t 创建并登记会话，id 为空时生成随机 ID；会话在 ctx 结束时关闭。
+// 返回的会话需调用 Serve 开始接收消息。
+func (m *Manager[T]) Open(ctx context.Context, id string, t Transport[T]) (*Session[T], error) {
+	if id == "" {
+		id = newID()
+	}
+	s := newSession(ctx, m, id, t)
+
+	m.mu.Lock()
+	switch {
+	case m.closed:
+		m.mu.Unlock()
+		s.cancel()
+		return nil, ErrManagerClosed
+	case m.sessions[id] != nil:
+		m.mu.Unlock()
+		s.cancel()
+		return nil, ErrSessionExists
+	}
+	m.sessions[id] = s
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	// 父 ctx 结束时关闭会话，例如 gRPC 流结束
+	stop := context.AfterFunc(ctx, func() {
+		s.close(context.Cause(ctx))
+	})
+	context.AfterFunc(s.ctx, func() {
+		stop()
+	})
+	if err := m.spawn(s); err != nil {
+		s.close(err)
+		return nil, err
+	}
+	for _, h := range hooks {
+		if h.OnOpen != nil {
+			h.OnOpen(s)
+		}
+	}
+	return s, nil
+}
+
+// spawn 启动会话的写协程，设置了协程池时在池中运行。
+func (m *Manager[T]) spawn(s *Session[T]) error {
+	run := func() (struct{}, error) {
+		s.run()
+		return struct{}{}, nil
+	}
+	if m.opts.pool == nil {
+		conc.Go(run)
+		return nil
+	}
+	f := m.opts.pool.Submit(run)
+	select {
+	case <-f.Inner():
+		// 写协程只在会话关闭后退出，提交后立即结束且带有错误说明提交失败
+		if err := f.Err(); err != nil {
+			close(s.stopped)
+			return err
+		}
+	default:
+	}
+	return nil
+}
+
+// remove 注销会话并执行 OnClose 钩子。
+func (m *Manager[T]) remove(s *Session[T], err error) {
+	m.mu.Lock()
+	if m.sessions[s.id] == s {
+		delete(m.sessions, s.id)
+	}
+	s.mu.Lock()
+	for g := range s.groups {
+		m.leaveLocked(s, g)
+	}
+	s.mu.Unlock()
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	if err != nil {
+		m.opts.logger.Debug("session closed",
+			logger.Field{Key: "session", Value: s.id},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+	for _, h := range hooks {
+		if h.OnClose != nil {
+			h.OnClose(s, err)
+		}
+	}
+}
+
+// heartbeat 执行 OnHeartbeat 钩子。
+func (m *Manager[T]) heartbeat(s *Session[T]) {
+	m.mu.RLock()
+	hooks := m.hooks
+	m.mu.RUnlock()
+	for _, h := range hooks {
+		if h.OnHeartbeat != nil {
+			h.OnHeartbeat(s)
+		}
+	}
+}
+
+// Get 返回指定 ID 的会话。
+func (m *Manager[T]) Get(id string) (*Session[T], bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[id]
+	return s, ok
+}
+
+// Len 返回活跃会话数量。
+func (m *Manager[T]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.sessions)
+}
+
+// Range 依次对每个活跃会话调用 fn，fn 返回 false 时停止。
+func (m *Manager[T]) Range(fn func(s *Session[T]) bool) {
+	for _, s := range m.snapshot() {
+		if !fn(s) {
+			return
+		}
+	}
+}
+
+// Push 向指定 ID 的会话发送消息，队列已满时按 Send 的规则等待。
+func (m *Manager[T]) Push(id string, msg T) error {
+	s, ok := m.Get(id)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return s.Send(msg)
+}
+
+// Broadcast 向所有活跃会话发送消息，返回成功放入发送队列的会话数量。
+// 广播不等待发送队列，队列已满的会话会错过这条消息。
+func (m *Manager[T]) Broadcast(msg T) int {
+	return m.broadcast(m.snapshot(), msg)
+}
+
+// Join 将会话加入分组。
+func (m *Manager[T]) Join(id, group string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	members := m.groups[group]
+	if members == nil {
+		members = make(map[string]*Session[T])
+		m.groups[group] = members
+	}
+	members[id] = s
+	s.mu.Lock()
+	s.groups[group] = struct{}{}
+	s.mu.Unlock()
+	return nil
+}
+
+// Leave 将会话移出分组。
+func (m *Manager[T]) Leave(id, group string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	m.leaveLocked(s, group)
+	s.mu.Unlock()
+}
+
+// leaveLocked 将会话移出分组，调用方需持有 m.mu 与 s.mu。
+func (m *Manager[T]) leaveLocked(s *Session[T], group string) {
+	delete(s.groups, group)
+	members := m.groups[group]
+	if members[s.id] != s {
+		return
+	}
+	delete(members, s.id)
+	if len(members) == 0 {
+		delete(m.groups, group)
+	}
+}
+
+// Members 返回分组内的会话 ID。
+func (m *Manager[T]) Members(group string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.groups[group]))
+	for id := range m.groups[group] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// PushGroup 向分组内的所有会话发送消息，返回成功放入发送队列的会话数量。
+// 与 Broadcast 相同，队列已满的会话会错过这条消息。
+func (m *Manager[T]) PushGroup(group string, msg T) int {
+	m.mu.RLock()
+	targets := make([]*Session[T], 0, len(m.groups[group]))
+	for _, s := range m.groups[group] {
+		targets = append(targets, s)
+	}
+	m.mu.RUnlock()
+	return m.broadcast(targets, msg)
+}
+
+func (m *Manager[T]) broadcast(targets []*Session[T], msg T) int {
+	sent := 0
+	for _, s := range targets {
+		if err := s.TrySend(msg); err != nil {
+			m.opts.logger.Warn("session push dropped",
+				logger.Field{Key: "session", Value: s.id},
+				logger.Field{Key: "error", Value: err},
+			)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+func (m *Manager[T]) snapshot() []*Session[T] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]*Session[T], 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// Close 拒绝新的会话，以 ErrManagerClosed 关闭所有活跃会话并等待其写协程退出；
+// ctx 结束时返回 ctx 的错误。
+func (m *Manager[T]) Close(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	sessions := m.snapshot()
+	for _, s := range sessions {
+		s.close(ErrManagerClosed)
+	}
+	for _, s := range sessions {
+		select {
+		case <-s.stopped:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
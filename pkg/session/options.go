@@ -0,0 +1,67 @@
+package session
+
+import (
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+// Config 会话配置。
+type Config struct {
+	// SendQueueSize 表示每个会话发送队列的容量。
+	SendQueueSize int `yaml:"send_queue_size"`
+	// SendTimeout 表示发送队列已满时 Send 的最长等待时间，0 表示不等待直接返回 ErrSendQueueFull。
+	SendTimeout time.Duration `yaml:"send_timeout"`
+	// HeartbeatInterval 表示调用 OnHeartbeat 钩子的间隔，0 表示不发送心跳。
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	// IdleTimeout 表示未收到任何消息时会话的最长存活时间，0 表示不检测空闲。
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+}
+
+// DefaultConfig 返回默认配置。
+func DefaultConfig() Config {
+	return Config{
+		SendQueueSize: 256,
+		SendTimeout:   time.Second,
+		IdleTimeout:   90 * time.Second,
+	}
+}
+
+// Option 会话管理器选项。
+type Option func(*options)
+
+type options struct {
+	config Config
+	logger logger.Logger
+	pool   *conc.Pool[struct{}]
+}
+
+func defaultOptions() options {
+	return options{
+		config: DefaultConfig(),
+		logger: logger.Nop(),
+	}
+}
+
+// WithConfig 设置会话配置。
+func WithConfig(cfg Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithLogger 设置日志记录器。
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithPool 设置运行会话写协程的协程池，未设置时每个会话单独启动协程。
+// 协程池的容量限制了同时存在的会话数量，非阻塞的协程池已满时 Open 返回错误。
+func WithPool(p *conc.Pool[struct{}]) Option {
+	return func(o *options) {
+		o.pool = p
+	}
+}
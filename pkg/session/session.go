@@ -0,0 +1,290 @@
+// Package session 将双向消息流抽象为会话：每个会话拥有唯一 ID、带背压的发送队列、
+// 心跳与空闲检测，并由 Manager 统一登记，支持按 ID、分组或全体推送消息。
+//
+// 会话与传输层无关，gRPC 双向流与网关的 TCP/WebSocket 连接均通过实现 Transport 接入。
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+var (
+	// ErrSessionClosed 表示会话已关闭。
+	ErrSessionClosed = errors.New("session: session closed")
+	// ErrSendQueueFull 表示发送队列已满，对端消费过慢。
+	ErrSendQueueFull = errors.New("session: send queue full")
+	// ErrIdleTimeout 表示会话在 IdleTimeout 内未收到任何消息而被关闭。
+	ErrIdleTimeout = errors.New("session: idle timeout")
+	// ErrSessionNotFound 表示指定 ID 的会话不存在。
+	ErrSessionNotFound = errors.New("session: session not found")
+	// ErrSessionExists 表示指定 ID 的会话已存在。
+	ErrSessionExists = errors.New("session: session already exists")
+	// ErrManagerClosed 表示会话管理器已关闭。
+	ErrManagerClosed = errors.New("session: manager closed")
+)
+
+// Transport 表示会话底层的双向消息流。
+type Transport[T any] interface {
+	// Send 发送一条消息，由会话的写协程串行调用。
+	Send(msg T) error
+	// Recv 阻塞接收下一条消息，由 Serve 串行调用；对端正常关闭时返回 io.EOF。
+	Recv() (T, error)
+	// Close 关闭底层连接并使阻塞中的 Recv 返回，无法主动关闭的传输层可以忽略。
+	Close() error
+	// RemoteAddr 返回对端地址。
+	RemoteAddr() string
+}
+
+// Handler 处理会话收到的消息，返回错误时关闭会话。
+type Handler[T any] func(s *Session[T], msg T) error
+
+// Session 表示一个双向消息流会话，可被多个协程并发发送消息：
+// 消息先进入发送队列，再由写协程串行写入传输层。
+type Session[T any] struct {
+	id        string
+	transport Transport[T]
+	manager   *Manager[T]
+	cfg       Config
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	queue      chan T
+	lastActive atomic.Int64
+	stopped    chan struct{}
+
+	mu     sync.Mutex
+	values map[string]any
+	groups map[string]struct{}
+
+	closeOnce sync.Once
+	err       error
+}
+
+func newSession[T any](ctx context.Context, m *Manager[T], id string, t Transport[T]) *Session[T] {
+	s := &Session[T]{
+		id:        id,
+		transport: t,
+		manager:   m,
+		cfg:       m.opts.config,
+		queue:     make(chan T, max(m.opts.config.SendQueueSize, 1)),
+		stopped:   make(chan struct{}),
+		values:    make(map[string]any),
+		groups:    make(map[string]struct{}),
+	}
+	// 会话的 ctx 只在 close 中结束，保证 Done 之后 Err 已确定；父 ctx 结束时由 Manager 关闭会话
+	ctx = logger.WithContextFields(context.WithoutCancel(ctx), logger.Field{Key: "session", Value: id})
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.touch()
+	return s
+}
+
+// ID 返回会话 ID。
+func (s *Session[T]) ID() string {
+	return s.id
+}
+
+// RemoteAddr 返回对端地址。
+func (s *Session[T]) RemoteAddr() string {
+	return s.transport.RemoteAddr()
+}
+
+// Context 返回会话的 ctx，会话关闭时结束。ctx 携带 session 日志字段，
+// 传给 Logger 的 *Context 方法即可在日志中关联该会话。
+func (s *Session[T]) Context() context.Context {
+	return s.ctx
+}
+
+// Done 返回会话关闭时关闭的通道。
+func (s *Session[T]) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Err 返回会话关闭的原因：正常关闭时为 nil，未关闭时也为 nil。
+func (s *Session[T]) Err() error {
+	select {
+	case <-s.ctx.Done():
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// LastActive 返回最近一次收到消息的时间。
+func (s *Session[T]) LastActive() time.Time {
+	return time.Unix(0, s.lastActive.Load())
+}
+
+// Set 设置会话属性，例如认证后的玩家 ID。
+func (s *Session[T]) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Get 返回会话属性。
+func (s *Session[T]) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Groups 返回会话所在的分组。
+func (s *Session[T]) Groups() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := make([]string, 0, len(s.groups))
+	for g := range s.groups {
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// Send 将消息放入发送队列，队列已满时最多等待 SendTimeout，超时返回 ErrSendQueueFull。
+func (s *Session[T]) Send(msg T) error {
+	if s.cfg.SendTimeout <= 0 {
+		return s.TrySend(msg)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.SendTimeout)
+	defer cancel()
+	err := s.SendContext(ctx, msg)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrSendQueueFull
+	}
+	return err
+}
+
+// SendContext 将消息放入发送队列，队列已满时等待直至 ctx 结束。
+func (s *Session[T]) SendContext(ctx context.Context, msg T) error {
+	if s.ctx.Err() != nil {
+		return ErrSessionClosed
+	}
+	select {
+	case s.queue <- msg:
+		return nil
+	case <-s.ctx.Done():
+		return ErrSessionClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TrySend 将消息放入发送队列，队列已满时立即返回 ErrSendQueueFull。
+func (s *Session[T]) TrySend(msg T) error {
+	if s.ctx.Err() != nil {
+		return ErrSessionClosed
+	}
+	select {
+	case s.queue <- msg:
+		return nil
+	default:
+		return ErrSendQueueFull
+	}
+}
+
+// Serve 循环接收消息并交给 handler 处理，直到会话关闭，返回会话关闭的原因。
+// 对端正常关闭（Recv 返回 io.EOF）时返回 nil。Serve 在写协程退出后才返回，
+// 因此 gRPC 流等要求处理函数返回后不再发送的传输可以直接在处理函数中调用。
+func (s *Session[T]) Serve(handler Handler[T]) error {
+	recv := conc.Go(func() (struct{}, error) {
+		for {
+			msg, err := s.transport.Recv()
+			if err != nil {
+				return struct{}{}, err
+			}
+			s.touch()
+			if err := handler(s, msg); err != nil {
+				return struct{}{}, err
+			}
+		}
+	})
+	select {
+	case <-recv.Inner():
+		s.close(recv.Err())
+	case <-s.ctx.Done():
+	}
+	// 等待写协程退出，保证返回后不再调用 Transport.Send
+	<-s.stopped
+	return s.Err()
+}
+
+// Close 关闭会话，队列中尚未发送的消息被丢弃。
+func (s *Session[T]) Close() {
+	s.close(nil)
+}
+
+// CloseWithError 以指定原因关闭会话。
+func (s *Session[T]) CloseWithError(err error) {
+	s.close(err)
+}
+
+func (s *Session[T]) close(err error) {
+	s.closeOnce.Do(func() {
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		s.err = err
+		s.cancel()
+		_ = s.transport.Close()
+		s.manager.remove(s, err)
+	})
+}
+
+func (s *Session[T]) touch() {
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+// run 是会话的写协程：串行写出发送队列中的消息，并负责心跳与空闲检测。
+func (s *Session[T]) run() {
+	defer close(s.stopped)
+	var heartbeat, idle <-chan time.Time
+	if s.cfg.HeartbeatInterval > 0 {
+		ticker := time.NewTicker(s.cfg.HeartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	var idleTimer *time.Timer
+	if s.cfg.IdleTimeout > 0 {
+		idleTimer = time.NewTimer(s.cfg.IdleTimeout)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg := <-s.queue:
+			if err := s.transport.Send(msg); err != nil {
+				s.close(err)
+				return
+			}
+		case <-heartbeat:
+			s.manager.heartbeat(s)
+		case <-idle:
+			remaining := s.cfg.IdleTimeout - time.Since(s.LastActive())
+			if remaining <= 0 {
+				s.close(ErrIdleTimeout)
+				return
+			}
+			idleTimer.Reset(remaining)
+		}
+	}
+}
+
+// newID 生成随机的 128 位十六进制会话 ID。
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,350 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lk2023060901/zeus-go/pkg/conc"
+	"github.com/lk2023060901/zeus-go/pkg/logger"
+)
+
+// pipe 是测试用的传输层：in 为对端发来的消息，out 记录写出的消息。
+type pipe struct {
+	in      chan string
+	out     chan string
+	closed  chan struct{}
+	once    sync.Once
+	block   chan struct{}
+	sendErr error
+}
+
+func newPipe() *pipe {
+	return &pipe{
+		in:     make(chan string, 16),
+		out:    make(chan string, 16),
+		closed: make(chan struct{}),
+	}
+}
+
+func (p *pipe) Send(msg string) error {
+	if p.block != nil {
+		<-p.block
+	}
+	if p.sendErr != nil {
+		return p.sendErr
+	}
+	p.out <- msg
+	return nil
+}
+
+func (p *pipe) Recv() (string, error) {
+	select {
+	case msg, ok := <-p.in:
+		if !ok {
+			return "", io.EOF
+		}
+		return msg, nil
+	case <-p.closed:
+		return "", errors.New("pipe closed")
+	}
+}
+
+func (p *pipe) Close() error {
+	p.once.Do(func() { close(p.closed) })
+	return nil
+}
+
+func (p *pipe) RemoteAddr() string {
+	return "127.0.0.1:1234"
+}
+
+func recvOut(t *testing.T, p *pipe) string {
+	t.Helper()
+	select {
+	case msg := <-p.out:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message sent")
+		return ""
+	}
+}
+
+func TestSessionServe(t *testing.T) {
+	m := NewManager[string]()
+	var opened, closed atomic.Int32
+	m.AddHooks(Hooks[string]{
+		OnOpen:  func(*Session[string]) { opened.Add(1) },
+		OnClose: func(_ *Session[string], err error) { closed.Add(1); assert.NoError(t, err) },
+	})
+
+	p := newPipe()
+	s, err := m.Open(context.Background(), "s1", p)
+	require.NoError(t, err)
+	assert.Equal(t, "s1", s.ID())
+	assert.Equal(t, "127.0.0.1:1234", s.RemoteAddr())
+	assert.Equal(t, int32(1), opened.Load())
+
+	_, err = m.Open(context.Background(), "s1", newPipe())
+	assert.ErrorIs(t, err, ErrSessionExists)
+
+	s.Set("player", 42)
+	v, ok := s.Get("player")
+	assert.True(t, ok)
+	assert.Equal(t, 42, v)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(func(s *Session[string], msg string) error {
+			return s.Send("echo " + msg)
+		})
+	}()
+	p.in <- "hi"
+	assert.Equal(t, "echo hi", recvOut(t, p))
+
+	// 对端正常关闭
+	close(p.in)
+	require.NoError(t, <-served)
+	assert.NoError(t, s.Err())
+	assert.Equal(t, 0, m.Len())
+	assert.Equal(t, int32(1), closed.Load())
+	assert.ErrorIs(t, s.Send("late"), ErrSessionClosed)
+	assert.ErrorIs(t, m.Push("s1", "late"), ErrSessionNotFound)
+}
+
+func TestSessionHandlerError(t *testing.T) {
+	m := NewManager[string]()
+	p := newPipe()
+	s, err := m.Open(context.Background(), "", p)
+	require.NoError(t, err)
+	assert.Len(t, s.ID(), 32)
+
+	boom := errors.New("boom")
+	p.in <- "bad"
+	assert.ErrorIs(t, s.Serve(func(*Session[string], string) error { return boom }), boom)
+	assert.ErrorIs(t, s.Err(), boom)
+	<-p.closed
+}
+
+func TestSessionBackpressure(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SendQueueSize = 1
+	cfg.SendTimeout = 20 * time.Millisecond
+	m := NewManager[string](WithConfig(cfg))
+
+	p := newPipe()
+	p.block = make(chan struct{})
+	s, err := m.Open(context.Background(), "", p)
+	require.NoError(t, err)
+
+	// 写协程阻塞在第一条消息上，第二条占满队列
+	require.NoError(t, s.Send("1"))
+	require.Eventually(t, func() bool { return len(s.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, s.Send("2"))
+	assert.ErrorIs(t, s.TrySend("3"), ErrSendQueueFull)
+	assert.ErrorIs(t, s.Send("3"), ErrSendQueueFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.SendContext(ctx, "3"), context.DeadlineExceeded)
+
+	close(p.block)
+	assert.Equal(t, "1", recvOut(t, p))
+	assert.Equal(t, "2", recvOut(t, p))
+	s.Close()
+}
+
+func TestSessionServeWaitsForWriter(t *testing.T) {
+	m := NewManager[string]()
+	p := newPipe()
+	p.block = make(chan struct{})
+	s, err := m.Open(context.Background(), "", p)
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(func(*Session[string], string) error { return nil })
+	}()
+	// 写协程阻塞在 Send 中时对端关闭，Serve 需等待写协程退出
+	require.NoError(t, s.Send("1"))
+	require.Eventually(t, func() bool { return len(s.queue) == 0 }, time.Second, time.Millisecond)
+	close(p.in)
+	select {
+	case <-served:
+		t.Fatal("Serve returned while the writer is still sending")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(p.block)
+	assert.Equal(t, "1", recvOut(t, p))
+	require.NoError(t, <-served)
+}
+
+func TestSessionSendError(t *testing.T) {
+	m := NewManager[string]()
+	p := newPipe()
+	p.sendErr = errors.New("broken pipe")
+	s, err := m.Open(context.Background(), "", p)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send("x"))
+	<-s.Done()
+	assert.ErrorIs(t, s.Err(), p.sendErr)
+}
+
+func TestSessionHeartbeatAndIdle(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HeartbeatInterval = 10 * time.Millisecond
+	cfg.IdleTimeout = 80 * time.Millisecond
+	m := NewManager[string](WithConfig(cfg))
+	m.AddHooks(Hooks[string]{
+		OnHeartbeat: func(s *Session[string]) { _ = s.TrySend("ping") },
+	})
+
+	p := newPipe()
+	s, err := m.Open(context.Background(), "", p)
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(func(*Session[string], string) error { return nil })
+	}()
+
+	assert.Equal(t, "ping", recvOut(t, p))
+	go func() {
+		for range p.out {
+		}
+	}()
+
+	// 持续收到消息时不会因空闲关闭
+	for i := 0; i < 6; i++ {
+		p.in <- "pong"
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.NoError(t, s.Err())
+
+	assert.ErrorIs(t, <-served, ErrIdleTimeout)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestSessionContextCanceled(t *testing.T) {
+	m := NewManager[string]()
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := m.Open(ctx, "", newPipe())
+	require.NoError(t, err)
+
+	cancel()
+	<-s.Done()
+	assert.ErrorIs(t, s.Err(), context.Canceled)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestSessionContextFields(t *testing.T) {
+	m := NewManager[string]()
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	s, err := m.Open(ctx, "player-1", newPipe())
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, []logger.Field{
+		{Key: logger.RequestIDKey, Value: "req-1"},
+		{Key: "session", Value: "player-1"},
+	}, logger.ContextFields(s.Context()))
+}
+
+func TestManagerGroups(t *testing.T) {
+	m := NewManager[string]()
+	pipes := make(map[string]*pipe)
+	for _, id := range []string{"a", "b", "c"} {
+		pipes[id] = newPipe()
+		_, err := m.Open(context.Background(), id, pipes[id])
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, m.Len())
+
+	require.NoError(t, m.Join("a", "room"))
+	require.NoError(t, m.Join("b", "room"))
+	assert.ErrorIs(t, m.Join("x", "room"), ErrSessionNotFound)
+	assert.ElementsMatch(t, []string{"a", "b"}, m.Members("room"))
+
+	a, _ := m.Get("a")
+	assert.Equal(t, []string{"room"}, a.Groups())
+
+	assert.Equal(t, 2, m.PushGroup("room", "hello room"))
+	assert.Equal(t, "hello room", recvOut(t, pipes["a"]))
+	assert.Equal(t, "hello room", recvOut(t, pipes["b"]))
+
+	require.NoError(t, m.Push("c", "direct"))
+	assert.Equal(t, "direct", recvOut(t, pipes["c"]))
+
+	assert.Equal(t, 3, m.Broadcast("all"))
+	for _, p := range pipes {
+		assert.Equal(t, "all", recvOut(t, p))
+	}
+
+	m.Leave("a", "room")
+	assert.Equal(t, []string{"b"}, m.Members("room"))
+	b, _ := m.Get("b")
+	b.Close()
+	assert.Empty(t, m.Members("room"))
+
+	count := 0
+	m.Range(func(*Session[string]) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, m.Close(context.Background()))
+	assert.Equal(t, 0, m.Len())
+	assert.ErrorIs(t, a.Err(), ErrManagerClosed)
+	_, err := m.Open(context.Background(), "", newPipe())
+	assert.ErrorIs(t, err, ErrManagerClosed)
+}
+
+func TestManagerPool(t *testing.T) {
+	pool := conc.NewPool[struct{}](1, conc.WithNonBlocking(true))
+	defer pool.Release()
+	m := NewManager[string](WithPool(pool))
+
+	s, err := m.Open(context.Background(), "", newPipe())
+	require.NoError(t, err)
+
+	// 协程池已满，无法再创建会话
+	_, err = m.Open(context.Background(), "", newPipe())
+	assert.Error(t, err)
+	assert.Equal(t, 1, m.Len())
+
+	s.Close()
+	require.NoError(t, m.Close(context.Background()))
+}
+
+func TestManagerConcurrentSend(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SendQueueSize = 1024
+	m := NewManager[string](WithConfig(cfg))
+	p := newPipe()
+	p.out = make(chan string, 1024)
+	s, err := m.Open(context.Background(), "", p)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, s.Send("msg"))
+			}
+		}()
+	}
+	wg.Wait()
+	require.Eventually(t, func() bool { return len(p.out) == 800 }, time.Second, time.Millisecond)
+	s.Close()
+}